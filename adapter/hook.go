@@ -0,0 +1,32 @@
+package adapter
+
+import "context"
+
+// HookError is returned when the document is written, but the OnChange hook fails. The write is not rolled back.
+type HookError struct {
+	Id  string
+	Err error
+}
+
+func (e *HookError) Error() string {
+	return "document " + e.Id + " is written, but OnChange failed: " + e.Err.Error()
+}
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// onChange calls the OnChange hook. The error is logged if LogError is set, so that the write is not reported as failed.
+func (a *Adapter[T]) onChange(ctx context.Context, id string, data map[string]interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	err := a.OnChange(ctx, id, data)
+	if err == nil {
+		return nil
+	}
+	if a.LogError != nil {
+		a.LogError(ctx, "OnChange of "+id+" failed: "+err.Error())
+		return nil
+	}
+	return &HookError{Id: id, Err: err}
+}
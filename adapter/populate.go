@@ -0,0 +1,16 @@
+package adapter
+
+import (
+	"context"
+
+	f "github.com/core-go/firestore"
+)
+
+// Populate loads the referenced documents of the models, see f.Populate.
+func (a *Adapter[T]) Populate(ctx context.Context, objs []T, fields ...string) error {
+	client, err := a.getClient(ctx)
+	if err != nil {
+		return err
+	}
+	return f.Populate[T](ctx, client, objs, fields...)
+}
@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"context"
+	"reflect"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+)
+
+// NewTenantAdapter creates the Adapter which resolves the collection from the tenant id in the context for each call, see f.NewTenantResolver.
+// Collection is nil, and the operations return f.ErrNoTenant if there is no tenant id in the context.
+func NewTenantAdapter[T any](client *firestore.Client, collectionPattern string, options ...string) *Adapter[T] {
+	return NewAdapterWithResolver[T](client, f.NewTenantResolver(client, collectionPattern), options...)
+}
+
+// NewAdapterWithResolver creates the Adapter which resolves the collection for each call. The collection must be in the database of client,
+// use NewAdapterWithClientResolver if the database is resolved for each call.
+func NewAdapterWithResolver[T any](client *firestore.Client, resolve f.CollectionResolver, options ...string) *Adapter[T] {
+	a := NewAdapter[T](client, "", options...)
+	a.Collection = nil
+	a.resolve = resolve
+	return a
+}
+
+// NewAdapterWithClientResolver creates the Adapter which resolves the client and the collection for each call, such as the client of the database in the context,
+// see client.Registry. If collectionName contains {tenantId}, it is replaced by the tenant id of the context.
+func NewAdapterWithClientResolver[T any](resolveClient f.ClientResolver, collectionName string, options ...string) *Adapter[T] {
+	a := NewAdapterWithResolver[T](nil, f.NewCollectionResolver(resolveClient, collectionName), options...)
+	a.resolveClient = resolveClient
+	return a
+}
+
+func NewTenantSearchAdapter[T any, F any](client *firestore.Client, collectionPattern string, buildQuery func(F) ([]f.Query, []string), getSort func(interface{}) string, options ...string) *SearchAdapter[T, F] {
+	return NewSearchAdapterWithResolver[T, F](client, f.NewTenantResolver(client, collectionPattern), buildQuery, f.BuildSort, getSort, options...)
+}
+func NewSearchAdapterWithResolver[T any, F any](client *firestore.Client, resolve f.CollectionResolver, buildQuery func(F) ([]f.Query, []string), buildSort func(string, reflect.Type) map[string]firestore.Direction, getSort func(interface{}) string, options ...string) *SearchAdapter[T, F] {
+	s := NewSearchAdapterWithSort[T, F](client, "", buildQuery, buildSort, getSort, options...)
+	s.Collection = nil
+	s.resolve = resolve
+	return s
+}
+
+func (a *Adapter[T]) collection(ctx context.Context) (*firestore.CollectionRef, error) {
+	if a.resolve == nil {
+		return a.Collection, nil
+	}
+	return a.resolve(ctx)
+}
+
+func (a *Adapter[T]) getClient(ctx context.Context) (*firestore.Client, error) {
+	if a.resolveClient == nil {
+		return a.client, nil
+	}
+	return a.resolveClient(ctx)
+}
+
+func NewSearchAdapterWithClientResolver[T any, F any](resolveClient f.ClientResolver, collectionName string, buildQuery func(F) ([]f.Query, []string), getSort func(interface{}) string, options ...string) *SearchAdapter[T, F] {
+	s := NewSearchAdapterWithResolver[T, F](nil, f.NewCollectionResolver(resolveClient, collectionName), buildQuery, f.BuildSort, getSort, options...)
+	s.resolveClient = resolveClient
+	return s
+}
@@ -0,0 +1,200 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+)
+
+// TxAdapter is the view of the Adapter bound to a transaction, its writes are committed with the transaction.
+// Load the documents before any write: Update, Patch and Delete reuse the documents loaded in the transaction.
+// The transaction must be in the database of the Adapter, see f.NewUnitOfWorkWithResolver.
+// The OnChange hook of the Adapter is not called, call it after the transaction is committed if it is needed.
+type TxAdapter[T any] struct {
+	base *Adapter[T]
+	tx   *f.Tx
+}
+
+func (a *Adapter[T]) WithTx(tx *f.Tx) *TxAdapter[T] {
+	return &TxAdapter[T]{base: a, tx: tx}
+}
+
+func (t *TxAdapter[T]) Load(ctx context.Context, id string) (*T, error) {
+	collection, err := t.base.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := t.tx.GetDocument(collection.Doc(id))
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	var obj T
+	err = doc.DataTo(&obj)
+	if err != nil {
+		return nil, err
+	}
+	f.BindCommonFields(&obj, doc, t.base.idIndex, t.base.createdTimeIndex, t.base.updatedTimeIndex)
+	return &obj, nil
+}
+
+// Create creates a copy of the model, the generated id, the version and the geohash are set to the model after the transaction is committed.
+func (t *TxAdapter[T]) Create(ctx context.Context, model *T) (int64, error) {
+	collection, err := t.base.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
+	// the model is copied, because the function of the transaction can be run again
+	obj := *model
+	mv := reflect.ValueOf(&obj).Elem()
+	id := mv.Field(t.base.idIndex).Interface().(string)
+	if t.base.geohashIndex >= 0 {
+		f.SetGeohash(&obj, t.base.locationIndex, t.base.geohashIndex)
+	}
+	if t.base.versionIndex >= 0 {
+		setVersion(mv, t.base.versionIndex)
+	}
+	var docRef *firestore.DocumentRef
+	if len(id) > 0 {
+		docRef = collection.Doc(id)
+	} else {
+		docRef = collection.NewDoc()
+		mv.Field(t.base.idIndex).Set(reflect.ValueOf(docRef.ID))
+	}
+	err = t.tx.Create(docRef, &obj)
+	if err != nil {
+		return -1, err
+	}
+	t.tx.AfterCommit(func() {
+		copyFields(reflect.ValueOf(model).Elem(), mv, t.base.idIndex, t.base.versionIndex, t.base.geohashIndex)
+	})
+	return 1, nil
+}
+
+// Update updates a copy of the model, the version, the geohash and the created time are set to the model after the transaction is committed.
+func (t *TxAdapter[T]) Update(ctx context.Context, model *T) (int64, error) {
+	collection, err := t.base.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
+	// the model is copied, because the function of the transaction can be run again
+	obj := *model
+	mv := reflect.ValueOf(&obj).Elem()
+	id := mv.Field(t.base.idIndex).Interface().(string)
+	docRef := collection.Doc(id)
+	doc, err := t.tx.GetDocument(docRef)
+	if err != nil {
+		return -1, err
+	}
+	if doc == nil {
+		return 0, nil
+	}
+	if t.base.geohashIndex >= 0 {
+		f.SetGeohash(&obj, t.base.locationIndex, t.base.geohashIndex)
+	}
+	if t.base.versionIndex >= 0 {
+		dbMap := doc.Data()
+		currentVersion := mv.Field(t.base.versionIndex).Interface()
+		scurrentVer := fmt.Sprintf("%v", currentVersion)
+		dbVer := fmt.Sprintf("%v", dbMap[t.base.versionFirestore])
+		if scurrentVer != dbVer {
+			return -1, nil
+		}
+		increaseVersion(mv, t.base.versionIndex, currentVersion)
+	}
+	err = t.tx.Set(docRef, &obj)
+	if err != nil {
+		return -1, err
+	}
+	if t.base.createdTimeIndex >= 0 {
+		mv.Field(t.base.createdTimeIndex).Set(reflect.ValueOf(&doc.CreateTime))
+	}
+	t.tx.AfterCommit(func() {
+		copyFields(reflect.ValueOf(model).Elem(), mv, t.base.versionIndex, t.base.geohashIndex, t.base.createdTimeIndex)
+	})
+	return 1, nil
+}
+
+// copyFields copies the fields at the indexes, the negative indexes are skipped.
+func copyFields(to reflect.Value, from reflect.Value, indexes ...int) {
+	for _, i := range indexes {
+		if i >= 0 {
+			to.Field(i).Set(from.Field(i))
+		}
+	}
+}
+func (t *TxAdapter[T]) Patch(ctx context.Context, data map[string]interface{}) (int64, error) {
+	collection, err := t.base.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
+	sid, ok := data[t.base.idJson]
+	if !ok {
+		return -1, fmt.Errorf("%s must be in map[string]interface{} for patch", t.base.idJson)
+	}
+	id := sid.(string)
+	// data is copied, because the function of the transaction can be run again
+	fields := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != t.base.idJson {
+			fields[k] = v
+		}
+	}
+	if err = t.base.patchGeohash(fields); err != nil {
+		return -1, err
+	}
+	docRef := collection.Doc(id)
+	doc, err := t.tx.GetDocument(docRef)
+	if err != nil {
+		return -1, err
+	}
+	if doc == nil {
+		return 0, nil
+	}
+	dbMap := doc.Data()
+	if t.base.versionIndex >= 0 {
+		currentVersion, vok := fields[t.base.versionJson]
+		if !vok {
+			return -1, fmt.Errorf("%s must be in model for patch", t.base.versionJson)
+		}
+		scurrentVer := fmt.Sprintf("%v", currentVersion)
+		dbVer := fmt.Sprintf("%v", dbMap[t.base.versionFirestore])
+		if scurrentVer != dbVer {
+			return -1, nil
+		}
+		increaseMapVersion(fields, t.base.versionJson, currentVersion)
+	}
+	fsMap := f.MapToFirestore(fields, dbMap, t.base.Map)
+	err = t.tx.Set(docRef, fsMap)
+	if err != nil {
+		return -1, err
+	}
+	if t.base.versionIndex >= 0 {
+		version := fields[t.base.versionJson]
+		t.tx.AfterCommit(func() {
+			data[t.base.versionJson] = version
+		})
+	}
+	return 1, nil
+}
+func (t *TxAdapter[T]) Delete(ctx context.Context, id string) (int64, error) {
+	collection, err := t.base.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
+	docRef := collection.Doc(id)
+	doc, err := t.tx.GetDocument(docRef)
+	if err != nil {
+		return -1, err
+	}
+	if doc == nil {
+		return 0, nil
+	}
+	err = t.tx.Delete(docRef)
+	if err != nil {
+		return -1, err
+	}
+	return 1, nil
+}
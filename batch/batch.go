@@ -3,6 +3,7 @@ package batch
 import (
 	"cloud.google.com/go/firestore"
 	"context"
+	"fmt"
 	"reflect"
 	"strings"
 )
@@ -57,6 +58,54 @@ func CreateMany[T any](ctx context.Context, client *firestore.Client, collection
 	return -1, nil
 }
 
+// CreateNew creates the models in one transaction, except the models whose documents exist, which are read with GetAll in the transaction.
+// A model with an empty id is created with a new id. It returns the indexes of the models which were not created because their documents exist.
+func CreateNew[T any](ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, models []T, idx int) ([]int, error) {
+	var existing []int
+	if len(models) == 0 {
+		return existing, nil
+	}
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		existing = make([]int, 0)
+		refs := make([]*firestore.DocumentRef, len(models))
+		reads := make([]*firestore.DocumentRef, 0, len(models))
+		for i := range models {
+			sid, _ := GetValueByIndex(models[i], idx).(string)
+			if len(sid) == 0 {
+				refs[i] = collection.NewDoc()
+			} else {
+				refs[i] = collection.Doc(sid)
+				reads = append(reads, refs[i])
+			}
+		}
+		exist := make(map[string]bool)
+		if len(reads) > 0 {
+			docs, er1 := tx.GetAll(reads)
+			if er1 != nil {
+				return er1
+			}
+			for _, doc := range docs {
+				if doc != nil && doc.Exists() {
+					exist[doc.Ref.ID] = true
+				}
+			}
+		}
+		for i := range models {
+			if exist[refs[i].ID] {
+				existing = append(existing, i)
+				continue
+			}
+			// the same id in the models is created once
+			exist[refs[i].ID] = true
+			if er2 := tx.Create(refs[i], models[i]); er2 != nil {
+				return er2
+			}
+		}
+		return nil
+	})
+	return existing, err
+}
+
 func SaveMany[T any](ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, models []T, opts ...int) (int, error) {
 	i := -1
 	le := len(models)
@@ -101,11 +150,8 @@ func SaveMany[T any](ctx context.Context, client *firestore.Client, collection *
 	return -1, nil
 }
 
-func UpdateMany[T any](ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, models []T, opts ...int) (int, error) {
-	le := len(models)
-	if le <= 0 {
-		return -1, nil
-	}
+// UpdateMany updates the existing documents only, and returns the ids of the missing documents, see UpdateExisting.
+func UpdateMany[T any](ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, models []T, opts ...int) ([]string, error) {
 	var idx int
 	if len(opts) > 0 && opts[0] >= 0 {
 		idx = opts[0]
@@ -117,39 +163,83 @@ func UpdateMany[T any](ctx context.Context, client *firestore.Client, collection
 		}
 		idx = FindIdField(modelType)
 	}
-	i := -1
+	missing, _, err := UpdateExisting[T](ctx, client, collection, models, idx, -1, "")
+	return missing, err
+}
+
+// UpdateExisting reads all documents with GetAll in one transaction, and updates the existing documents only.
+// If versionIndex >= 0, a document is updated only if its version equals the version of the model, then the version of the model is increased.
+// It returns the ids of the missing documents and the ids of the documents which have a different version.
+// It returns an error without writing if a model has an empty id, or if two models have the same id.
+func UpdateExisting[T any](ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, models []T, idx int, versionIndex int, versionFirestore string) ([]string, []string, error) {
+	var missing, conflicts []string
+	var updated []int
+	if len(models) == 0 {
+		return missing, conflicts, nil
+	}
+	ids := make(map[string]int, len(models))
+	for i := range models {
+		sid, _ := GetValueByIndex(models[i], idx).(string)
+		if len(sid) == 0 {
+			return missing, conflicts, fmt.Errorf("id of the model at index %d is empty", i)
+		}
+		if j, ok := ids[sid]; ok {
+			return missing, conflicts, fmt.Errorf("models at index %d and %d have the same id %s", j, i, sid)
+		}
+		ids[sid] = i
+	}
 	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
-		for i = 0; i < le; i++ {
-			value := models[i]
-			id := GetValueByIndex(value, idx)
-			sid := id.(string)
-			ref := collection.NewDoc()
-			if len(id.(string)) > 0 {
-				ref = collection.Doc(sid)
-				data, err := ref.Get(ctx)
-				if err != nil {
-					if !strings.HasSuffix(err.Error(), " not found") {
-						er2 := tx.Set(ref, value)
-						if er2 != nil {
-							return er2
-						}
-					}
+		missing = make([]string, 0)
+		conflicts = make([]string, 0)
+		updated = make([]int, 0)
+		refs := make([]*firestore.DocumentRef, 0, len(models))
+		positions := make([]int, 0, len(models))
+		for i := range models {
+			sid, _ := GetValueByIndex(models[i], idx).(string)
+			refs = append(refs, collection.Doc(sid))
+			positions = append(positions, i)
+		}
+		docs, er1 := tx.GetAll(refs)
+		if er1 != nil {
+			return er1
+		}
+		for j, doc := range docs {
+			if doc == nil || !doc.Exists() {
+				missing = append(missing, refs[j].ID)
+				continue
+			}
+			vo := reflect.Indirect(reflect.ValueOf(&models[positions[j]]).Elem())
+			if versionIndex >= 0 {
+				currentVersion := vo.Field(versionIndex).Interface()
+				dbVersion, _ := doc.DataAt(versionFirestore)
+				if fmt.Sprintf("%v", currentVersion) != fmt.Sprintf("%v", dbVersion) {
+					conflicts = append(conflicts, refs[j].ID)
+					continue
 				}
-				var er2 error
-				if data != nil || data.Exists() {
-					er2 = tx.Set(ref, value)
-					if er2 != nil {
-						return er2
-					}
+				// the model is encoded by Set, so the version is restored until the transaction is committed
+				increaseVersion(vo, versionIndex, currentVersion)
+				er2 := tx.Set(refs[j], models[positions[j]])
+				vo.Field(versionIndex).Set(reflect.ValueOf(currentVersion))
+				if er2 != nil {
+					return er2
 				}
+				updated = append(updated, positions[j])
+				continue
+			}
+			er2 := tx.Set(refs[j], models[positions[j]])
+			if er2 != nil {
+				return er2
 			}
 		}
 		return nil
 	})
-	if err != nil {
-		return i, err
+	if err == nil {
+		for _, i := range updated {
+			vo := reflect.Indirect(reflect.ValueOf(&models[i]).Elem())
+			increaseVersion(vo, versionIndex, vo.Field(versionIndex).Interface())
+		}
 	}
-	return -1, nil
+	return missing, conflicts, err
 }
 
 func GetValueByIndex(model interface{}, idx int) interface{} {
@@ -4,6 +4,7 @@ import (
 	"cloud.google.com/go/firestore"
 	"context"
 	"fmt"
+	f "github.com/core-go/firestore"
 	"reflect"
 )
 
@@ -12,6 +13,9 @@ type BatchCreator[T any] struct {
 	collection *firestore.CollectionRef
 	Idx        int
 	Map        func(*T)
+	// resolveClient and resolveCollection resolve the client and the collection for each write if they are set
+	resolveClient     f.ClientResolver
+	resolveCollection f.CollectionResolver
 }
 
 func NewBatchCreator[T any](client *firestore.Client, collectionName string, opts ...func(*T)) *BatchCreator[T] {
@@ -32,7 +36,10 @@ func NewBatchCreator[T any](client *firestore.Client, collectionName string, opt
 	if len(opts) >= 1 {
 		mp = opts[0]
 	}
-	collection := client.Collection(collectionName)
+	var collection *firestore.CollectionRef
+	if client != nil {
+		collection = client.Collection(collectionName)
+	}
 	return &BatchCreator[T]{client: client, collection: collection, Idx: idx, Map: mp}
 }
 
@@ -46,5 +53,33 @@ func (w *BatchCreator[T]) Write(ctx context.Context, models []T) (int, error) {
 			w.Map(&models[i])
 		}
 	}
-	return CreateMany[T](ctx, w.client, w.collection, models, w.Idx)
+	client, collection, err := resolve(ctx, w.client, w.collection, w.resolveClient, w.resolveCollection)
+	if err != nil {
+		return 0, err
+	}
+	return CreateMany[T](ctx, client, collection, models, w.Idx)
+}
+
+// Create creates the models whose documents do not exist, it returns the indexes of the models whose documents exist.
+func (w *BatchCreator[T]) Create(ctx context.Context, models []T) ([]int, error) {
+	if w.Map != nil {
+		l := len(models)
+		for i := 0; i < l; i++ {
+			w.Map(&models[i])
+		}
+	}
+	client, collection, err := resolve(ctx, w.client, w.collection, w.resolveClient, w.resolveCollection)
+	if err != nil {
+		return nil, err
+	}
+	return CreateNew[T](ctx, client, collection, models, w.Idx)
+}
+
+// NewBatchCreatorWithResolver creates the BatchCreator which resolves the client and the collection for each write, such as the client of the database in the context.
+// If collectionName contains {tenantId}, it is replaced by the tenant id of the context.
+func NewBatchCreatorWithResolver[T any](resolveClient f.ClientResolver, collectionName string, opts ...func(*T)) *BatchCreator[T] {
+	w := NewBatchCreator[T](nil, "", opts...)
+	w.resolveClient = resolveClient
+	w.resolveCollection = f.NewCollectionResolver(resolveClient, collectionName)
+	return w
 }
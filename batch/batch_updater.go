@@ -4,6 +4,7 @@ import (
 	"cloud.google.com/go/firestore"
 	"context"
 	"fmt"
+	f "github.com/core-go/firestore"
 	"reflect"
 )
 
@@ -12,6 +13,11 @@ type BatchUpdater[T any] struct {
 	collection *firestore.CollectionRef
 	Idx        int
 	Map        func(*T)
+	// resolveClient and resolveCollection resolve the client and the collection for each write if they are set
+	resolveClient     f.ClientResolver
+	resolveCollection f.CollectionResolver
+	versionIndex      int
+	versionFirestore  string
 }
 
 func NewBatchUpdater[T any](client *firestore.Client, collectionName string, opts ...func(*T)) *BatchUpdater[T] {
@@ -32,19 +38,62 @@ func NewBatchUpdater[T any](client *firestore.Client, collectionName string, opt
 	if len(opts) >= 1 {
 		mp = opts[0]
 	}
-	collection := client.Collection(collectionName)
-	return &BatchUpdater[T]{client, collection, idx, mp}
+	var collection *firestore.CollectionRef
+	if client != nil {
+		collection = client.Collection(collectionName)
+	}
+	return &BatchUpdater[T]{client: client, collection: collection, Idx: idx, Map: mp, versionIndex: -1}
+}
+
+// NewBatchUpdaterWithVersion creates the updater which updates a document only if its version equals the version of the model, then increases the version.
+func NewBatchUpdaterWithVersion[T any](client *firestore.Client, collectionName string, versionField string, opts ...func(*T)) *BatchUpdater[T] {
+	w := NewBatchUpdater[T](client, collectionName, opts...)
+	var t T
+	modelType := reflect.TypeOf(t)
+	index, _, versionFirestore := f.FindFieldByName(modelType, versionField)
+	if index < 0 {
+		panic(fmt.Sprintf("%s struct requires version field which name is '%s'", modelType.Name(), versionField))
+	}
+	vn := modelType.Field(index).Type.String()
+	if !(vn == "int" || vn == "int32" || vn == "int64") {
+		panic(fmt.Sprintf("%s type of %s struct must be int or int32 or int64", versionField, modelType.Name()))
+	}
+	w.versionIndex = index
+	w.versionFirestore = versionFirestore
+	return w
 }
 
 func (w *BatchUpdater[T]) Write(ctx context.Context, models []T) (int, error) {
 	if len(models) == 0 {
 		return -1, nil
 	}
+	_, _, err := w.Update(ctx, models)
+	if err != nil {
+		return 0, err
+	}
+	return -1, nil
+}
+
+// Update updates the existing documents only, it returns the ids of the missing documents and of the documents which have a different version.
+func (w *BatchUpdater[T]) Update(ctx context.Context, models []T) ([]string, []string, error) {
 	if w.Map != nil {
 		l := len(models)
 		for i := 0; i < l; i++ {
 			w.Map(&models[i])
 		}
 	}
-	return UpdateMany[T](ctx, w.client, w.collection, models, w.Idx)
+	client, collection, err := resolve(ctx, w.client, w.collection, w.resolveClient, w.resolveCollection)
+	if err != nil {
+		return nil, nil, err
+	}
+	return UpdateExisting[T](ctx, client, collection, models, w.Idx, w.versionIndex, w.versionFirestore)
+}
+
+// NewBatchUpdaterWithResolver creates the BatchUpdater which resolves the client and the collection for each write, such as the client of the database in the context.
+// If collectionName contains {tenantId}, it is replaced by the tenant id of the context.
+func NewBatchUpdaterWithResolver[T any](resolveClient f.ClientResolver, collectionName string, opts ...func(*T)) *BatchUpdater[T] {
+	w := NewBatchUpdater[T](nil, "", opts...)
+	w.resolveClient = resolveClient
+	w.resolveCollection = f.NewCollectionResolver(resolveClient, collectionName)
+	return w
 }
@@ -4,6 +4,7 @@ import (
 	"cloud.google.com/go/firestore"
 	"context"
 	"fmt"
+	f "github.com/core-go/firestore"
 	"reflect"
 )
 
@@ -12,6 +13,9 @@ type BatchWriter[T any] struct {
 	collection *firestore.CollectionRef
 	Idx        int
 	Map        func(*T)
+	// resolveClient and resolveCollection resolve the client and the collection for each write if they are set
+	resolveClient     f.ClientResolver
+	resolveCollection f.CollectionResolver
 }
 
 func NewBatchWriterWithIdName[T any](client *firestore.Client, collectionName string, opts ...func(*T)) *BatchWriter[T] {
@@ -32,7 +36,10 @@ func NewBatchWriterWithIdName[T any](client *firestore.Client, collectionName st
 	if len(opts) >= 1 {
 		mp = opts[0]
 	}
-	collection := client.Collection(collectionName)
+	var collection *firestore.CollectionRef
+	if client != nil {
+		collection = client.Collection(collectionName)
+	}
 	return &BatchWriter[T]{client: client, collection: collection, Idx: idx, Map: mp}
 }
 func (w *BatchWriter[T]) Write(ctx context.Context, models []T) (int, error) {
@@ -45,5 +52,18 @@ func (w *BatchWriter[T]) Write(ctx context.Context, models []T) (int, error) {
 			w.Map(&models[i])
 		}
 	}
-	return SaveMany[T](ctx, w.client, w.collection, models, w.Idx)
+	client, collection, err := resolve(ctx, w.client, w.collection, w.resolveClient, w.resolveCollection)
+	if err != nil {
+		return 0, err
+	}
+	return SaveMany[T](ctx, client, collection, models, w.Idx)
+}
+
+// NewBatchWriterWithResolver creates the BatchWriter which resolves the client and the collection for each write, such as the client of the database in the context.
+// If collectionName contains {tenantId}, it is replaced by the tenant id of the context.
+func NewBatchWriterWithResolver[T any](resolveClient f.ClientResolver, collectionName string, opts ...func(*T)) *BatchWriter[T] {
+	w := NewBatchWriterWithIdName[T](nil, "", opts...)
+	w.resolveClient = resolveClient
+	w.resolveCollection = f.NewCollectionResolver(resolveClient, collectionName)
+	return w
 }
@@ -1,8 +1,12 @@
 package batch
 
 import (
+	"context"
 	"reflect"
 	"strings"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
 )
 
 func FindIdField(modelType reflect.Type) int {
@@ -23,3 +27,19 @@ func findBsonField(modelType reflect.Type, bsonName string) int {
 	}
 	return -1
 }
+
+// resolve returns the client and the collection of the call, they are resolved from the context if resolveClient is set.
+func resolve(ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, resolveClient f.ClientResolver, resolveCollection f.CollectionResolver) (*firestore.Client, *firestore.CollectionRef, error) {
+	if resolveClient == nil {
+		return client, collection, nil
+	}
+	c, err := resolveClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	col, err := resolveCollection(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, col, nil
+}
@@ -43,6 +43,16 @@ func (w *StreamUpdater[T]) Write(ctx context.Context, model T) error {
 	if w.Map != nil {
 		w.Map(model)
 	}
+	// UpdateMany rejects duplicate ids, so the batch is flushed before the same id is added again
+	sid, _ := GetValueByIndex(model, w.Idx).(string)
+	for i := range w.batch {
+		if id, _ := GetValueByIndex(w.batch[i], w.Idx).(string); id == sid {
+			if err := w.Flush(ctx); err != nil {
+				return err
+			}
+			break
+		}
+	}
 	w.batch = append(w.batch, model)
 	le := len(w.batch)
 	if le >= w.batchSize {
@@ -4,6 +4,7 @@ import (
 	"cloud.google.com/go/firestore"
 	"context"
 	"fmt"
+	f "github.com/core-go/firestore"
 	"reflect"
 )
 
@@ -14,6 +15,9 @@ type StreamWriter[T any] struct {
 	batch      []T
 	batchSize  int
 	Map        func(T)
+	resolve    f.CollectionResolver
+	// resolveClient resolves the client for each write if it is set
+	resolveClient f.ClientResolver
 }
 
 func NewStreamWriter[T any](client *firestore.Client, collectionName string, batchSize int, opts ...func(T)) *StreamWriter[T] {
@@ -34,12 +38,54 @@ func NewStreamWriter[T any](client *firestore.Client, collectionName string, bat
 	if len(opts) >= 1 {
 		mp = opts[0]
 	}
-	collection := client.Collection(collectionName)
+	var collection *firestore.CollectionRef
+	if client != nil {
+		collection = client.Collection(collectionName)
+	}
 	batch := make([]T, 0)
 	return &StreamWriter[T]{client: client, collection: collection, Idx: idx, Map: mp, batchSize: batchSize, batch: batch}
 }
 
+// NewTenantStreamWriter creates the stream writer which resolves the collection from the tenant id in the context for each write, see f.NewTenantResolver.
+// The pending models are flushed when the collection of the tenant changes.
+func NewTenantStreamWriter[T any](client *firestore.Client, collectionPattern string, batchSize int, opts ...func(T)) *StreamWriter[T] {
+	w := NewStreamWriter[T](client, "", batchSize, opts...)
+	w.collection = nil
+	w.resolve = f.NewTenantResolver(client, collectionPattern)
+	return w
+}
+
+// NewStreamWriterWithResolver creates the stream writer which resolves the client and the collection for each write, such as the client of the database in the context.
+// If collectionName contains {tenantId}, it is replaced by the tenant id of the context. The pending models are flushed when the client or the collection changes.
+func NewStreamWriterWithResolver[T any](resolveClient f.ClientResolver, collectionName string, batchSize int, opts ...func(T)) *StreamWriter[T] {
+	w := NewStreamWriter[T](nil, "", batchSize, opts...)
+	w.resolveClient = resolveClient
+	w.resolve = f.NewCollectionResolver(resolveClient, collectionName)
+	return w
+}
+
 func (w *StreamWriter[T]) Write(ctx context.Context, model T) error {
+	if w.resolve != nil {
+		client := w.client
+		if w.resolveClient != nil {
+			c, err := w.resolveClient(ctx)
+			if err != nil {
+				return err
+			}
+			client = c
+		}
+		collection, err := w.resolve(ctx)
+		if err != nil {
+			return err
+		}
+		if w.collection == nil || w.collection.Path != collection.Path || w.client != client {
+			if err = w.Flush(ctx); err != nil {
+				return err
+			}
+			w.client = client
+			w.collection = collection
+		}
+	}
 	if w.Map != nil {
 		w.Map(model)
 	}
@@ -0,0 +1,38 @@
+package batch
+
+import (
+	"cloud.google.com/go/firestore"
+	"context"
+)
+
+// UpdateFields applies the same updates to the documents, in one transaction for each chunk of chunkSize documents.
+// It returns the number of updated documents.
+func UpdateFields(ctx context.Context, client *firestore.Client, refs []*firestore.DocumentRef, updates []firestore.Update, chunkSize int) (int, error) {
+	if len(refs) == 0 || len(updates) == 0 {
+		return 0, nil
+	}
+	if chunkSize <= 0 || chunkSize > 500 {
+		chunkSize = 500
+	}
+	count := 0
+	for start := 0; start < len(refs); start += chunkSize {
+		end := start + chunkSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+		chunk := refs[start:end]
+		err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			for _, ref := range chunk {
+				if er1 := tx.Update(ref, updates); er1 != nil {
+					return er1
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return count, err
+		}
+		count = count + len(chunk)
+	}
+	return count, nil
+}
@@ -0,0 +1,23 @@
+package batch
+
+import "reflect"
+
+func increaseVersion(vo reflect.Value, versionIndex int, curVer interface{}) bool {
+	versionType := vo.Field(versionIndex).Type().String()
+	switch versionType {
+	case "int32":
+		nextVer := curVer.(int32) + 1
+		vo.Field(versionIndex).Set(reflect.ValueOf(nextVer))
+		return true
+	case "int":
+		nextVer := curVer.(int) + 1
+		vo.Field(versionIndex).Set(reflect.ValueOf(nextVer))
+		return true
+	case "int64":
+		nextVer := curVer.(int64) + 1
+		vo.Field(versionIndex).Set(reflect.ValueOf(nextVer))
+		return true
+	default:
+		return false
+	}
+}
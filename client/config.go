@@ -1,6 +1,10 @@
 package client
 
 type Config struct {
-	ProjectId   string `yaml:"project_id" mapstructure:"project_id" json:"projectId,omitempty" gorm:"column:projectid" bson:"projectId,omitempty" dynamodbav:"projectId,omitempty" firestore:"projectId,omitempty"`
-	Credentials string `yaml:"credentials" mapstructure:"credentials" json:"credentials,omitempty" gorm:"column:credentials" bson:"credentials,omitempty" dynamodbav:"credentials,omitempty" firestore:"credentials,omitempty"`
+	ProjectId       string `yaml:"project_id" mapstructure:"project_id" json:"projectId,omitempty" gorm:"column:projectid" bson:"projectId,omitempty" dynamodbav:"projectId,omitempty" firestore:"projectId,omitempty"`
+	Credentials     string `yaml:"credentials" mapstructure:"credentials" json:"credentials,omitempty" gorm:"column:credentials" bson:"credentials,omitempty" dynamodbav:"credentials,omitempty" firestore:"credentials,omitempty"`
+	CredentialsFile string `yaml:"credentials_file" mapstructure:"credentials_file" json:"credentialsFile,omitempty" gorm:"column:credentialsfile" bson:"credentialsFile,omitempty" dynamodbav:"credentialsFile,omitempty" firestore:"credentialsFile,omitempty"`
+	DatabaseId      string `yaml:"database_id" mapstructure:"database_id" json:"databaseId,omitempty" gorm:"column:databaseid" bson:"databaseId,omitempty" dynamodbav:"databaseId,omitempty" firestore:"databaseId,omitempty"`
+	EmulatorHost    string `yaml:"emulator_host" mapstructure:"emulator_host" json:"emulatorHost,omitempty" gorm:"column:emulatorhost" bson:"emulatorHost,omitempty" dynamodbav:"emulatorHost,omitempty" firestore:"emulatorHost,omitempty"`
+	PoolSize        int    `yaml:"pool_size" mapstructure:"pool_size" json:"poolSize,omitempty" gorm:"column:poolsize" bson:"poolSize,omitempty" dynamodbav:"poolSize,omitempty" firestore:"poolSize,omitempty"`
 }
@@ -3,22 +3,107 @@ package client
 import (
 	"cloud.google.com/go/firestore"
 	"context"
+	"errors"
 	firebase "firebase.google.com/go"
+	"fmt"
 	"google.golang.org/api/option"
-	"log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"os"
+)
+
+const (
+	EmulatorHost      = "FIRESTORE_EMULATOR_HOST"
+	EmulatorProjectId = "demo-project"
 )
 
 func Connect(ctx context.Context, credentials []byte) (*firestore.Client, error) {
 	app, er1 := firebase.NewApp(ctx, nil, option.WithCredentialsJSON(credentials))
 	if er1 != nil {
-		log.Fatalf("Could not create admin client: %v", er1)
-		return nil, er1
+		return nil, fmt.Errorf("could not create admin client: %w", er1)
 	}
 
 	client, er2 := app.Firestore(ctx)
 	if er2 != nil {
-		log.Fatalf("Could not create data operations client: %v", er2)
-		return nil, er2
+		return nil, fmt.Errorf("could not create data operations client: %w", er2)
 	}
 	return client, nil
 }
+
+// NewClient creates the client from the config, and returns the function which closes it.
+// If EmulatorHost is set, this client connects to the emulator without credentials, other clients are not changed.
+// If FIRESTORE_EMULATOR_HOST is set, all clients connect to the emulator.
+// The connection to the emulator is not closed by the Close of the client, so call the returned function instead.
+func NewClient(ctx context.Context, c Config) (*firestore.Client, func() error, error) {
+	emulator := len(c.EmulatorHost) > 0 || len(os.Getenv(EmulatorHost)) > 0
+	projectId := c.ProjectId
+	if len(projectId) == 0 {
+		if emulator {
+			projectId = EmulatorProjectId
+		} else {
+			projectId = firestore.DetectProjectID
+		}
+	}
+	opts := ClientOptions(c, emulator)
+	var conn *grpc.ClientConn
+	if len(c.EmulatorHost) > 0 {
+		var err error
+		conn, err = grpc.NewClient(c.EmulatorHost, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithPerRPCCredentials(emulatorCredentials{}))
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = []option.ClientOption{option.WithGRPCConn(conn)}
+	}
+	var client *firestore.Client
+	var err error
+	if len(c.DatabaseId) > 0 {
+		client, err = firestore.NewClientWithDatabase(ctx, projectId, c.DatabaseId, opts...)
+	} else {
+		client, err = firestore.NewClient(ctx, projectId, opts...)
+	}
+	if err == nil && client == nil {
+		err = errors.New("could not create firestore client")
+	}
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, nil, err
+	}
+	if conn == nil {
+		return client, client.Close, nil
+	}
+	return client, func() error {
+		err := client.Close()
+		if er2 := conn.Close(); er2 != nil && err == nil {
+			err = er2
+		}
+		return err
+	}, nil
+}
+
+// ClientOptions returns the credentials and pool size options, credentials are ignored for the emulator.
+func ClientOptions(c Config, emulator bool) []option.ClientOption {
+	opts := make([]option.ClientOption, 0)
+	if !emulator {
+		if len(c.Credentials) > 0 {
+			opts = append(opts, option.WithCredentialsJSON([]byte(c.Credentials)))
+		} else if len(c.CredentialsFile) > 0 {
+			opts = append(opts, option.WithCredentialsFile(c.CredentialsFile))
+		}
+	}
+	if c.PoolSize > 0 {
+		opts = append(opts, option.WithGRPCConnectionPool(c.PoolSize))
+	}
+	return opts
+}
+
+// emulatorCredentials sends the "owner" token, which the emulator accepts without security rules.
+type emulatorCredentials struct{}
+
+func (emulatorCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer owner"}, nil
+}
+func (emulatorCredentials) RequireTransportSecurity() bool {
+	return false
+}
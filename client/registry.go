@@ -0,0 +1,152 @@
+package client
+
+import (
+	"cloud.google.com/go/firestore"
+	"context"
+	"fmt"
+	"sync"
+
+	f "github.com/core-go/firestore"
+)
+
+const DefaultDatabaseId = "(default)"
+
+type databaseKey struct{}
+
+// WithDatabase returns a context which routes to the named database.
+func WithDatabase(ctx context.Context, databaseId string) context.Context {
+	return context.WithValue(ctx, databaseKey{}, databaseId)
+}
+
+// GetDatabase returns the database id of the context, or an empty string.
+func GetDatabase(ctx context.Context) string {
+	if databaseId, ok := ctx.Value(databaseKey{}).(string); ok {
+		return databaseId
+	}
+	return ""
+}
+
+// Registry keeps one client per database id, the clients are created on first use.
+type Registry struct {
+	mu      sync.Mutex
+	configs map[string]Config
+	clients map[string]*firestore.Client
+	closers map[string]func() error
+	Default string
+	// Resolve returns the database id for a context, such as the database of a tenant. By default, it is GetDatabase.
+	Resolve func(ctx context.Context) string
+}
+
+// NewRegistry creates the registry from configs keyed by their DatabaseId, the first config is the default database.
+func NewRegistry(configs ...Config) *Registry {
+	r := &Registry{configs: make(map[string]Config), clients: make(map[string]*firestore.Client), closers: make(map[string]func() error), Resolve: GetDatabase}
+	for i, c := range configs {
+		id := getDatabaseId(c.DatabaseId)
+		r.configs[id] = c
+		if i == 0 {
+			r.Default = id
+		}
+	}
+	return r
+}
+
+// Add registers an existing client for the database id. The client is owned by the caller, it is not closed by Close.
+func (r *Registry) Add(databaseId string, client *firestore.Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := getDatabaseId(databaseId)
+	r.clients[id] = client
+	delete(r.closers, id)
+	if len(r.Default) == 0 {
+		r.Default = id
+	}
+}
+
+// Get returns the client of the database id, an empty id is the default database.
+func (r *Registry) Get(ctx context.Context, databaseId string) (*firestore.Client, error) {
+	id := databaseId
+	if len(id) == 0 {
+		id = r.Default
+	}
+	id = getDatabaseId(id)
+	r.mu.Lock()
+	client, ok := r.clients[id]
+	c, configured := r.configs[id]
+	r.mu.Unlock()
+	if ok {
+		return client, nil
+	}
+	if !configured {
+		return nil, fmt.Errorf("database '%s' is not configured", id)
+	}
+	// connect outside the lock, so that the other databases are not blocked
+	client, closeClient, err := NewClient(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.clients[id]; ok {
+		closeClient()
+		return existing, nil
+	}
+	r.clients[id] = client
+	r.closers[id] = closeClient
+	return client, nil
+}
+
+// Client returns the client of the database resolved from the context.
+func (r *Registry) Client(ctx context.Context) (*firestore.Client, error) {
+	var id string
+	if r.Resolve != nil {
+		id = r.Resolve(ctx)
+	}
+	return r.Get(ctx, id)
+}
+
+// NewCollectionResolver returns the resolver of the collection in the database of the context.
+// If collectionName contains {tenantId}, it is replaced by the tenant id of the context.
+// Use r.Client as the f.ClientResolver of the adapters, loaders and writers, so that they also use the client of the database.
+func (r *Registry) NewCollectionResolver(collectionName string) f.CollectionResolver {
+	return f.NewCollectionResolver(r.Client, collectionName)
+}
+
+// Databases returns the configured and added database ids.
+func (r *Registry) Databases() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.configs))
+	for id := range r.configs {
+		ids = append(ids, id)
+	}
+	for id := range r.clients {
+		if _, ok := r.configs[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Close closes the clients created by the registry, and forgets the clients added by Add without closing them.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var err error
+	for id := range r.clients {
+		if closeClient, ok := r.closers[id]; ok {
+			if er1 := closeClient(); er1 != nil && err == nil {
+				err = er1
+			}
+		}
+		delete(r.clients, id)
+		delete(r.closers, id)
+	}
+	return err
+}
+
+func getDatabaseId(databaseId string) string {
+	if len(databaseId) == 0 {
+		return DefaultDatabaseId
+	}
+	return databaseId
+}
@@ -0,0 +1,111 @@
+package counter
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// Counter is a sharded counter: the count of a counter document is split into Shards documents of a sub collection,
+// so that the counter can be incremented more than once per second.
+type Counter struct {
+	client     *firestore.Client
+	Collection *firestore.CollectionRef
+	Shards     int
+	shardName  string
+	countName  string
+}
+
+// NewCounter creates the counter, options are the name of the shard sub collection ("shards") and the name of the count field ("count").
+func NewCounter(client *firestore.Client, collectionName string, shards int, options ...string) *Counter {
+	if shards <= 0 || shards > 500 {
+		panic("shards must be from 1 to 500")
+	}
+	shardName := "shards"
+	countName := "count"
+	if len(options) > 0 && len(options[0]) > 0 {
+		shardName = options[0]
+	}
+	if len(options) > 1 && len(options[1]) > 0 {
+		countName = options[1]
+	}
+	return &Counter{client: client, Collection: client.Collection(collectionName), Shards: shards, shardName: shardName, countName: countName}
+}
+
+func (c *Counter) shards(id string) *firestore.CollectionRef {
+	return c.Collection.Doc(id).Collection(c.shardName)
+}
+
+// Init creates the counter document and its shards with count 0, the existing shards are reset.
+func (c *Counter) Init(ctx context.Context, id string) error {
+	return c.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		err := tx.Set(c.Collection.Doc(id), map[string]interface{}{"shards": c.Shards}, firestore.MergeAll)
+		if err != nil {
+			return err
+		}
+		shards := c.shards(id)
+		for i := 0; i < c.Shards; i++ {
+			err = tx.Set(shards.Doc(strconv.Itoa(i)), map[string]interface{}{c.countName: 0})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Increment adds n to a random shard. The shard is created if it does not exist, so Init is optional.
+func (c *Counter) Increment(ctx context.Context, id string, n int64) error {
+	shard := c.shards(id).Doc(strconv.Itoa(rand.Intn(c.Shards)))
+	_, err := shard.Set(ctx, map[string]interface{}{c.countName: firestore.Increment(n)}, firestore.MergeAll)
+	return err
+}
+
+// Get returns the total by reading and summing all shards.
+func (c *Counter) Get(ctx context.Context, id string) (int64, error) {
+	iter := c.shards(id).Select(c.countName).Documents(ctx)
+	defer iter.Stop()
+	var total int64
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+		v, err := doc.DataAt(c.countName)
+		if err != nil {
+			continue
+		}
+		total = total + toInt64(v)
+	}
+}
+
+// Sum returns the total by a sum aggregation query, which is billed as one read per 1000 shards.
+func (c *Counter) Sum(ctx context.Context, id string) (int64, error) {
+	res, err := c.shards(id).NewAggregationQuery().WithSum(c.countName, "total").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := res.Data()["total"]
+	if !ok {
+		return 0, fmt.Errorf("sum of %s is not found", c.countName)
+	}
+	return toInt64(v), nil
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	default:
+		return 0
+	}
+}
@@ -8,22 +8,35 @@ import (
 
 	"cloud.google.com/go/firestore"
 	f "github.com/core-go/firestore"
-	"google.golang.org/api/iterator"
 )
 
 type Dao[T any] struct {
+	client           *firestore.Client
 	Collection       *firestore.CollectionRef
 	ModelType        reflect.Type
 	idIndex          int
 	idJson           string
 	createdTimeIndex int
+	createdTimeJson  string
 	updatedTimeIndex int
 	updatedTimeJson  string
 	Map              map[string]string
+	jsonMap          map[string]string
 	versionField     string
 	versionJson      string
 	versionFirestore string
 	versionIndex     int
+	locationIndex    int
+	geohashIndex     int
+	locationJson     string
+	geohashJson      string
+	resolve          f.CollectionResolver
+	resolveClient    f.ClientResolver
+	jsonIndex        map[string]int
+	// OnChange is called after a document is updated or patched, with the changed fields keyed by firestore names.
+	// If it fails, the error is logged by LogError, or returned as *HookError if LogError is nil.
+	OnChange func(ctx context.Context, id string, data map[string]interface{}) error
+	LogError func(ctx context.Context, msg string)
 }
 
 func NewDao[T any](client *firestore.Client, collectionName string, options ...string) *Dao[T] {
@@ -57,7 +70,7 @@ func NewDao[T any](client *firestore.Client, collectionName string, options ...s
 			panic(fmt.Sprintf("%s struct requires id field which has bson tag '_id'", modelType.Name()))
 		}
 	} else {
-		idx, _, _ = f.FindFieldByName(modelType, idFieldName)
+		idx, idJson, _ = f.FindFieldByName(modelType, idFieldName)
 		if idx < 0 {
 			panic(fmt.Sprintf("%s struct requires id field which id name is '%s'", modelType.Name(), idFieldName))
 		}
@@ -67,8 +80,9 @@ func NewDao[T any](client *firestore.Client, collectionName string, options ...s
 		panic(fmt.Sprintf("%s type of %s struct must be string", modelType.Field(idx).Name, modelType.Name()))
 	}
 	ctIdx := -1
+	var createdTimeJson string
 	if len(createdTimeFieldName) >= 0 {
-		ctIdx, _, _ = f.FindFieldByName(modelType, createdTimeFieldName)
+		ctIdx, createdTimeJson, _ = f.FindFieldByName(modelType, createdTimeFieldName)
 		if ctIdx >= 0 {
 			ctn := modelType.Field(ctIdx).Type.String()
 			if ctn != "*time.Time" {
@@ -88,7 +102,11 @@ func NewDao[T any](client *firestore.Client, collectionName string, options ...s
 		}
 	}
 	maps := f.MakeFirestoreMap(modelType)
-	adapter := &Dao[T]{Collection: client.Collection(collectionName), ModelType: modelType, idIndex: idx, idJson: idJson, Map: maps, createdTimeIndex: ctIdx, updatedTimeIndex: utIdx, updatedTimeJson: updatedTimeJson, versionIndex: versionIndex}
+	var collection *firestore.CollectionRef
+	if client != nil {
+		collection = client.Collection(collectionName)
+	}
+	adapter := &Dao[T]{client: client, Collection: collection, ModelType: modelType, idIndex: idx, idJson: idJson, Map: maps, jsonMap: f.MakeJsonMap(modelType), jsonIndex: f.MakeJsonIndex(modelType), createdTimeIndex: ctIdx, createdTimeJson: createdTimeJson, updatedTimeIndex: utIdx, updatedTimeJson: updatedTimeJson, versionIndex: versionIndex, locationIndex: -1, geohashIndex: -1}
 	if len(versionField) > 0 {
 		index, versionJson, versionFirestore := f.FindFieldByName(modelType, versionField)
 		if index >= 0 {
@@ -106,52 +124,34 @@ func NewDao[T any](client *firestore.Client, collectionName string, options ...s
 }
 
 func (a *Dao[T]) All(ctx context.Context) ([]T, error) {
-	iter := a.Collection.Documents(ctx)
-	var objs []T
-	for {
-		doc, er1 := iter.Next()
-		if er1 == iterator.Done {
-			break
-		}
-		if er1 != nil {
-			return nil, er1
-		}
-		var obj T
-		er2 := doc.DataTo(&obj)
-		if er2 != nil {
-			return objs, er2
-		}
-
-		f.BindCommonFields(&obj, doc, a.idIndex, a.createdTimeIndex, a.updatedTimeIndex)
-
-		objs = append(objs, obj)
-	}
-	return objs, nil
+	return a.AllFields(ctx, nil)
 }
 
 func (a *Dao[T]) Load(ctx context.Context, id string) (*T, error) {
-	var obj T
-	ok, doc, err := f.Load(ctx, a.Collection, id, &obj)
-	if err != nil {
-		return nil, err
-	}
-	if !ok {
-		return nil, nil
-	}
-	f.BindCommonFields(&obj, doc, a.idIndex, a.createdTimeIndex, a.updatedTimeIndex)
-	return &obj, nil
+	return a.LoadFields(ctx, id, nil)
 }
 
 func (a *Dao[T]) Exist(ctx context.Context, id string) (bool, error) {
-	return f.Exist(ctx, a.Collection, id)
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return false, err
+	}
+	return f.Exist(ctx, collection, id)
 }
 func (a *Dao[T]) Create(ctx context.Context, model *T) (int64, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
 	mv := reflect.Indirect(reflect.ValueOf(model))
 	id := mv.Field(a.idIndex).Interface().(string)
+	if a.geohashIndex >= 0 {
+		f.SetGeohash(model, a.locationIndex, a.geohashIndex)
+	}
 	if a.versionIndex >= 0 {
 		setVersion(mv, a.versionIndex)
 	}
-	res, rid, updateTime, err := f.Create(ctx, a.Collection, id, model)
+	res, rid, updateTime, err := f.Create(ctx, collection, id, model)
 	if len(id) == 0 && len(rid) > 0 {
 		fv := mv.Field(a.idIndex)
 		fv.Set(reflect.ValueOf(rid))
@@ -169,13 +169,20 @@ func (a *Dao[T]) Create(ctx context.Context, model *T) (int64, error) {
 	return res, err
 }
 func (a *Dao[T]) Save(ctx context.Context, model *T) (int64, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
 	mv := reflect.Indirect(reflect.ValueOf(model))
 	id := mv.Field(a.idIndex).Interface().(string)
 	if len(id) == 0 {
 		return a.Create(ctx, model)
 	}
+	if a.geohashIndex >= 0 {
+		f.SetGeohash(model, a.locationIndex, a.geohashIndex)
+	}
 	if a.versionIndex < 0 {
-		res, updateTime, err := f.Save(ctx, a.Collection, id, model)
+		res, updateTime, err := f.Save(ctx, collection, id, model)
 		if updateTime != nil {
 			if a.updatedTimeIndex >= 0 {
 				cv := mv.Field(a.createdTimeIndex)
@@ -184,12 +191,12 @@ func (a *Dao[T]) Save(ctx context.Context, model *T) (int64, error) {
 		}
 		return res, err
 	}
-	docRef := a.Collection.Doc(id)
+	docRef := collection.Doc(id)
 	doc, er0 := docRef.Get(ctx)
 	if er0 != nil {
 		if strings.HasSuffix(er0.Error(), " not found") {
 			setVersion(mv, a.versionIndex)
-			res, _, updateTime, err := f.Create(ctx, a.Collection, id, model)
+			res, _, updateTime, err := f.Create(ctx, collection, id, model)
 			if updateTime != nil {
 				if a.createdTimeIndex >= 0 {
 					cv := mv.Field(a.createdTimeIndex)
@@ -228,28 +235,52 @@ func (a *Dao[T]) Save(ctx context.Context, model *T) (int64, error) {
 }
 
 func (a *Dao[T]) Update(ctx context.Context, model *T) (int64, error) {
+	res, doc, err := a.updateModel(ctx, model)
+	if err != nil || res <= 0 || a.OnChange == nil {
+		return res, err
+	}
+	id := reflect.Indirect(reflect.ValueOf(model)).Field(a.idIndex).Interface().(string)
+	data := f.ToFirestoreMap(model, a.jsonIndex, a.Map)
+	if doc != nil {
+		data = f.GetChangedFields(doc.Data(), data)
+	}
+	return res, a.onChange(ctx, id, data)
+}
+
+// updateModel updates the document of the model, it returns the document before the update if it was read.
+func (a *Dao[T]) updateModel(ctx context.Context, model *T) (int64, *firestore.DocumentSnapshot, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return -1, nil, err
+	}
 	mv := reflect.Indirect(reflect.ValueOf(model))
 	id := mv.Field(a.idIndex).Interface().(string)
-	if a.versionIndex >= 0 {
-		docRef := a.Collection.Doc(id)
+	if a.geohashIndex >= 0 {
+		f.SetGeohash(model, a.locationIndex, a.geohashIndex)
+	}
+	if a.versionIndex >= 0 || a.OnChange != nil {
+		// the document is read to check the version, or to pass the changed fields to OnChange
+		docRef := collection.Doc(id)
 		doc, er0 := docRef.Get(ctx)
 		if er0 != nil {
 			if strings.HasSuffix(er0.Error(), " not found") {
-				return 0, nil
+				return 0, nil, nil
 			}
-			return -1, er0
+			return -1, nil, er0
 		}
-		dbMap := doc.Data()
-		currentVersion := mv.Field(a.versionIndex).Interface()
-		scurrentVer := fmt.Sprintf("%v", currentVersion)
-		dbVer := fmt.Sprintf("%v", dbMap[a.versionFirestore])
-		if scurrentVer != dbVer {
-			return -1, nil
+		if a.versionIndex >= 0 {
+			dbMap := doc.Data()
+			currentVersion := mv.Field(a.versionIndex).Interface()
+			scurrentVer := fmt.Sprintf("%v", currentVersion)
+			dbVer := fmt.Sprintf("%v", dbMap[a.versionFirestore])
+			if scurrentVer != dbVer {
+				return -1, nil, nil
+			}
+			increaseVersion(mv, a.versionIndex, currentVersion)
 		}
-		increaseVersion(mv, a.versionIndex, currentVersion)
 		res, err := docRef.Set(ctx, model)
 		if err != nil {
-			return -1, err
+			return -1, nil, err
 		}
 		if a.createdTimeIndex >= 0 {
 			cv := mv.Field(a.createdTimeIndex)
@@ -259,59 +290,81 @@ func (a *Dao[T]) Update(ctx context.Context, model *T) (int64, error) {
 			cv := mv.Field(a.updatedTimeIndex)
 			cv.Set(reflect.ValueOf(&res.UpdateTime))
 		}
-		return 1, nil
+		return 1, doc, nil
 	}
-	res, updateTime, err := f.Update(ctx, a.Collection, id, model)
+	res, updateTime, err := f.Update(ctx, collection, id, model)
 	if updateTime != nil {
 		if a.updatedTimeIndex >= 0 {
 			cv := mv.Field(a.createdTimeIndex)
 			cv.Set(reflect.ValueOf(updateTime))
 		}
 	}
-	return res, err
+	return res, nil, err
 }
 
+// Patch updates the fields of the map only, the keys are json names. It reads the document only to check the version.
 func (a *Dao[T]) Patch(ctx context.Context, data map[string]interface{}) (int64, error) {
+	sid, _ := data[a.idJson].(string)
+	res, err := a.patch(ctx, data)
+	if err != nil || res <= 0 || a.OnChange == nil {
+		return res, err
+	}
+	return res, a.onChange(ctx, sid, f.MapToFirestore(data, make(map[string]interface{}), a.Map))
+}
+func (a *Dao[T]) patch(ctx context.Context, data map[string]interface{}) (int64, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
+	if err = a.patchGeohash(data); err != nil {
+		return -1, err
+	}
 	sid, ok := data[a.idJson]
 	if !ok {
 		return -1, fmt.Errorf("%s must be in map[string]interface{} for patch", a.idJson)
 	}
 	id := sid.(string)
 	delete(data, a.idJson)
-	docRef := a.Collection.Doc(id)
-	doc, er0 := docRef.Get(ctx)
-	if er0 != nil {
-		if strings.HasSuffix(er0.Error(), " not found") {
-			return 0, nil
-		}
-		return -1, er0
-	}
-	dbMap := doc.Data()
+	var currentVersion, version interface{}
 	if a.versionIndex >= 0 {
-		currentVersion, vok := data[a.versionJson]
+		var vok bool
+		currentVersion, vok = data[a.versionJson]
 		if !vok {
 			return -1, fmt.Errorf("%s must be in model for patch", a.versionJson)
 		}
-		scurrentVer := fmt.Sprintf("%v", currentVersion)
-		dbVer := fmt.Sprintf("%v", dbMap[a.versionFirestore])
-		if scurrentVer != dbVer {
-			return -1, nil
+		version, vok = nextVersion(currentVersion)
+		if !vok {
+			return -1, fmt.Errorf("%s must be an integer", a.versionJson)
 		}
-		increaseMapVersion(data, a.versionJson, currentVersion)
+		delete(data, a.versionJson)
 	}
-	fsMap := f.MapToFirestore(data, dbMap, a.Map)
-	res, err := docRef.Set(ctx, fsMap)
+	updates, err := f.MapToUpdates(data, a.Map)
 	if err != nil {
+		if a.versionIndex >= 0 {
+			data[a.versionJson] = currentVersion
+		}
 		return -1, err
 	}
-	if len(a.updatedTimeJson) >= 0 {
-		data[a.updatedTimeJson] = res.UpdateTime
+	res, updateTime, err := a.update(ctx, collection.Doc(id), updates, currentVersion, version)
+	if a.versionIndex >= 0 {
+		if res > 0 {
+			data[a.versionJson] = version
+		} else {
+			data[a.versionJson] = currentVersion
+		}
+	}
+	if updateTime != nil && len(a.updatedTimeJson) > 0 {
+		data[a.updatedTimeJson] = *updateTime
 	}
-	return 1, nil
+	return res, err
 }
 
 func (a *Dao[T]) Delete(ctx context.Context, id string) (int64, error) {
-	return f.Delete(ctx, a.Collection, id)
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
+	return f.Delete(ctx, collection, id)
 }
 
 func setVersion(vo reflect.Value, versionIndex int) bool {
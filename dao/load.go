@@ -0,0 +1,20 @@
+package dao
+
+import (
+	"context"
+
+	f "github.com/core-go/firestore"
+)
+
+// LoadMany loads the models in the order of ids, with nil for the missing documents, see f.LoadMany.
+func (a *Dao[T]) LoadMany(ctx context.Context, ids []string) ([]*T, []string, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := a.getClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f.LoadMany[T](ctx, client, collection, ids, a.idIndex, a.createdTimeIndex, a.updatedTimeIndex, nil)
+}
@@ -0,0 +1,107 @@
+package dao
+
+import (
+	"context"
+
+	f "github.com/core-go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// AllFields loads all documents with the given json fields only. The id and create/update times are always bound.
+// The fields which are not loaded keep their zero values, use AllMap to tell them from loaded zero values.
+func (a *Dao[T]) AllFields(ctx context.Context, fields []string) ([]T, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.ToFirestoreFields(fields, a.Map)
+	if err != nil {
+		return nil, err
+	}
+	iter := f.SelectFields(collection, names).Documents(ctx)
+	var objs []T
+	for {
+		doc, er1 := iter.Next()
+		if er1 == iterator.Done {
+			break
+		}
+		if er1 != nil {
+			return nil, er1
+		}
+		var obj T
+		er2 := doc.DataTo(&obj)
+		if er2 != nil {
+			return objs, er2
+		}
+
+		f.BindCommonFields(&obj, doc, a.idIndex, a.createdTimeIndex, a.updatedTimeIndex)
+
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// LoadFields loads the document with the given json fields only. The id and create/update times are always bound.
+// The fields which are not loaded keep their zero values, use LoadMap to tell them from loaded zero values.
+func (a *Dao[T]) LoadFields(ctx context.Context, id string, fields []string) (*T, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.ToFirestoreFields(fields, a.Map)
+	if err != nil {
+		return nil, err
+	}
+	var obj T
+	ok, doc, err := f.LoadFields(ctx, collection, id, names, &obj)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+	f.BindCommonFields(&obj, doc, a.idIndex, a.createdTimeIndex, a.updatedTimeIndex)
+	return &obj, nil
+}
+
+// AllMap loads all documents with the given json fields only, keyed by json names.
+func (a *Dao[T]) AllMap(ctx context.Context, fields []string) ([]map[string]interface{}, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.ToFirestoreFields(fields, a.Map)
+	if err != nil {
+		return nil, err
+	}
+	iter := f.SelectFields(collection, names).Documents(ctx)
+	objs := make([]map[string]interface{}, 0)
+	for {
+		doc, er1 := iter.Next()
+		if er1 == iterator.Done {
+			break
+		}
+		if er1 != nil {
+			return nil, er1
+		}
+		objs = append(objs, f.ToJsonMap(doc, a.jsonMap, a.idJson, a.createdTimeJson, a.updatedTimeJson))
+	}
+	return objs, nil
+}
+
+// LoadMap loads the document with the given json fields only, keyed by json names.
+func (a *Dao[T]) LoadMap(ctx context.Context, id string, fields []string) (map[string]interface{}, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.ToFirestoreFields(fields, a.Map)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := f.LoadDocument(ctx, collection, id, names)
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	return f.ToJsonMap(doc, a.jsonMap, a.idJson, a.createdTimeJson, a.updatedTimeJson), nil
+}
@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"context"
+	"reflect"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+)
+
+// NewTenantDao creates the Dao which resolves the collection from the tenant id in the context for each call, see f.NewTenantResolver.
+// Collection is nil, and the operations return f.ErrNoTenant if there is no tenant id in the context.
+func NewTenantDao[T any](client *firestore.Client, collectionPattern string, options ...string) *Dao[T] {
+	return NewDaoWithResolver[T](client, f.NewTenantResolver(client, collectionPattern), options...)
+}
+
+// NewDaoWithResolver creates the Dao which resolves the collection for each call. The collection must be in the database of client,
+// use NewDaoWithClientResolver if the database is resolved for each call.
+func NewDaoWithResolver[T any](client *firestore.Client, resolve f.CollectionResolver, options ...string) *Dao[T] {
+	a := NewDao[T](client, "", options...)
+	a.Collection = nil
+	a.resolve = resolve
+	return a
+}
+
+// NewDaoWithClientResolver creates the Dao which resolves the client and the collection for each call, such as the client of the database in the context,
+// see client.Registry. If collectionName contains {tenantId}, it is replaced by the tenant id of the context.
+func NewDaoWithClientResolver[T any](resolveClient f.ClientResolver, collectionName string, options ...string) *Dao[T] {
+	a := NewDaoWithResolver[T](nil, f.NewCollectionResolver(resolveClient, collectionName), options...)
+	a.resolveClient = resolveClient
+	return a
+}
+
+func NewTenantSearchDao[T any, F any](client *firestore.Client, collectionPattern string, buildQuery func(F) ([]f.Query, []string), getSort func(interface{}) string, options ...string) *SearchDao[T, F] {
+	return NewSearchDaoWithResolver[T, F](client, f.NewTenantResolver(client, collectionPattern), buildQuery, f.BuildSort, getSort, options...)
+}
+func NewSearchDaoWithResolver[T any, F any](client *firestore.Client, resolve f.CollectionResolver, buildQuery func(F) ([]f.Query, []string), buildSort func(string, reflect.Type) map[string]firestore.Direction, getSort func(interface{}) string, options ...string) *SearchDao[T, F] {
+	s := NewSearchDaoWithSort[T, F](client, "", buildQuery, buildSort, getSort, options...)
+	s.Collection = nil
+	s.resolve = resolve
+	return s
+}
+
+func (a *Dao[T]) collection(ctx context.Context) (*firestore.CollectionRef, error) {
+	if a.resolve == nil {
+		return a.Collection, nil
+	}
+	return a.resolve(ctx)
+}
+
+func (a *Dao[T]) getClient(ctx context.Context) (*firestore.Client, error) {
+	if a.resolveClient == nil {
+		return a.client, nil
+	}
+	return a.resolveClient(ctx)
+}
+
+func NewSearchDaoWithClientResolver[T any, F any](resolveClient f.ClientResolver, collectionName string, buildQuery func(F) ([]f.Query, []string), getSort func(interface{}) string, options ...string) *SearchDao[T, F] {
+	s := NewSearchDaoWithResolver[T, F](nil, f.NewCollectionResolver(resolveClient, collectionName), buildQuery, f.BuildSort, getSort, options...)
+	s.resolveClient = resolveClient
+	return s
+}
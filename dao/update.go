@@ -0,0 +1,114 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UpdateFields updates the given fields of the model only, the fields are json names. It returns 0 if there is no field to update.
+func (a *Dao[T]) UpdateFields(ctx context.Context, model *T, fields []string) (int64, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
+	mv := reflect.Indirect(reflect.ValueOf(model))
+	id := mv.Field(a.idIndex).Interface().(string)
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field != a.idJson && (a.versionIndex < 0 || field != a.versionJson) {
+			names = append(names, field)
+		}
+	}
+	if a.geohashIndex >= 0 {
+		f.SetGeohash(model, a.locationIndex, a.geohashIndex)
+	}
+	updates, err := f.BuildUpdates(model, a.geohashFields(names), a.jsonIndex, a.Map)
+	if err != nil {
+		return -1, err
+	}
+	var currentVersion, version interface{}
+	if a.versionIndex >= 0 {
+		currentVersion = mv.Field(a.versionIndex).Interface()
+		version, _ = nextVersion(currentVersion)
+	}
+	res, updateTime, err := a.update(ctx, collection.Doc(id), updates, currentVersion, version)
+	if res > 0 {
+		if a.versionIndex >= 0 {
+			increaseVersion(mv, a.versionIndex, currentVersion)
+		}
+		if updateTime != nil && a.updatedTimeIndex >= 0 {
+			mv.Field(a.updatedTimeIndex).Set(reflect.ValueOf(updateTime))
+		}
+		if a.OnChange != nil {
+			data := make(map[string]interface{}, len(updates))
+			for _, u := range updates {
+				data[u.FieldPath[0]] = u.Value
+			}
+			return res, a.onChange(ctx, id, data)
+		}
+	}
+	return res, err
+}
+
+// UpdateNonZero updates the fields of the model which are not zero.
+func (a *Dao[T]) UpdateNonZero(ctx context.Context, model *T) (int64, error) {
+	return a.UpdateFields(ctx, model, f.GetNonZeroFields(model, a.jsonIndex, a.idIndex, a.versionIndex))
+}
+
+// update updates the document without reading it. If the Dao has a version field, it reads the document to check the version,
+// and updates it only if the document was not changed after the read.
+// It returns 0 without calling Firestore if there is no field to update.
+func (a *Dao[T]) update(ctx context.Context, docRef *firestore.DocumentRef, updates []firestore.Update, currentVersion interface{}, version interface{}) (int64, *time.Time, error) {
+	if len(updates) == 0 {
+		return 0, nil, nil
+	}
+	var preconditions []firestore.Precondition
+	if a.versionIndex >= 0 {
+		doc, er0 := docRef.Get(ctx)
+		if er0 != nil {
+			if status.Code(er0) == codes.NotFound {
+				return 0, nil, nil
+			}
+			return -1, nil, er0
+		}
+		dbVer := fmt.Sprintf("%v", doc.Data()[a.versionFirestore])
+		if fmt.Sprintf("%v", currentVersion) != dbVer {
+			return -1, nil, nil
+		}
+		updates = append(updates, firestore.Update{FieldPath: firestore.FieldPath{a.versionFirestore}, Value: version})
+		preconditions = append(preconditions, firestore.LastUpdateTime(doc.UpdateTime))
+	}
+	res, err := docRef.Update(ctx, updates, preconditions...)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return 0, nil, nil
+		}
+		if status.Code(err) == codes.FailedPrecondition {
+			return -1, nil, nil
+		}
+		return -1, nil, err
+	}
+	return 1, &res.UpdateTime, nil
+}
+
+func nextVersion(currentVersion interface{}) (interface{}, bool) {
+	switch v := currentVersion.(type) {
+	case int32:
+		return v + 1, true
+	case int:
+		return v + 1, true
+	case int64:
+		return v + 1, true
+	case float64:
+		return int64(v) + 1, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,142 @@
+package denormalize
+
+import (
+	"context"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+	"github.com/core-go/firestore/batch"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Rule copies the Fields of the Source documents into the Target documents whose Key field is the id of the source document.
+// Fields maps the firestore names of the source fields to the firestore names of the target fields.
+type Rule struct {
+	Source string            `json:"source,omitempty"`
+	Fields map[string]string `json:"fields,omitempty"`
+	Target string            `json:"target,omitempty"`
+	Key    string            `json:"key,omitempty"`
+}
+
+type Syncer struct {
+	client    *firestore.Client
+	Rules     []Rule
+	ChunkSize int
+	LogError  func(ctx context.Context, msg string)
+}
+
+func NewSyncer(client *firestore.Client, chunkSize int, rules ...Rule) *Syncer {
+	return &Syncer{client: client, Rules: rules, ChunkSize: chunkSize}
+}
+
+// Sync updates the target documents of the rules of the source collection, data is the fields of the source document, keyed by firestore names.
+// Only the target documents which have different values are updated, so unchanged fields do not cause writes.
+// Source and Target can be patterns with {tenantId}, the target is resolved from the tenant id of the context.
+// It returns the number of updated documents.
+func (s *Syncer) Sync(ctx context.Context, source string, id string, data map[string]interface{}) (int64, error) {
+	var count int64
+	for _, rule := range s.Rules {
+		if rule.Source != source {
+			continue
+		}
+		updates := make([]firestore.Update, 0, len(rule.Fields))
+		names := make([]string, 0, len(rule.Fields))
+		for x, y := range rule.Fields {
+			if v, ok := data[x]; ok {
+				updates = append(updates, firestore.Update{FieldPath: firestore.FieldPath{y}, Value: v})
+				names = append(names, y)
+			}
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		target, err := s.collection(ctx, rule.Target)
+		if err != nil {
+			return count, err
+		}
+		docs, err := target.Where(rule.Key, "==", id).Select(names...).Documents(ctx).GetAll()
+		if err != nil {
+			return count, err
+		}
+		refs := make([]*firestore.DocumentRef, 0, len(docs))
+		for _, doc := range docs {
+			if isChanged(doc, updates) {
+				refs = append(refs, doc.Ref)
+			}
+		}
+		n, err := batch.UpdateFields(ctx, s.client, refs, updates, s.ChunkSize)
+		count = count + int64(n)
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+func (s *Syncer) collection(ctx context.Context, path string) (*firestore.CollectionRef, error) {
+	if strings.Contains(path, f.TenantId) {
+		return f.NewTenantResolver(s.client, path)(ctx)
+	}
+	return s.client.Collection(path), nil
+}
+
+func isChanged(doc *firestore.DocumentSnapshot, updates []firestore.Update) bool {
+	for _, u := range updates {
+		v, err := doc.DataAt(u.FieldPath[0])
+		if err != nil || !f.IsEqual(v, u.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Hook returns the function to be set as the OnChange hook of the Adapter of the source collection.
+func (s *Syncer) Hook(source string) func(ctx context.Context, id string, data map[string]interface{}) error {
+	return func(ctx context.Context, id string, data map[string]interface{}) error {
+		_, err := s.Sync(ctx, source, id, data)
+		return err
+	}
+}
+
+// Listen syncs the modified documents of the source collection until the context is done.
+// If source is a pattern with {tenantId}, it listens to the collection of the tenant of the context.
+// All fields of a modified document are compared, because the snapshot does not have the previous values.
+// The first snapshot has all documents of the collection, so Listen reads the whole collection when it starts, and this first snapshot is skipped.
+func (s *Syncer) Listen(ctx context.Context, source string) error {
+	collection, err := s.collection(ctx, source)
+	if err != nil {
+		return err
+	}
+	iter := collection.Snapshots(ctx)
+	defer iter.Stop()
+	first := true
+	for {
+		snapshot, err := iter.Next()
+		if err != nil {
+			if err == iterator.Done || status.Code(err) == codes.Canceled || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if first {
+			// the first snapshot has all documents as added
+			first = false
+			continue
+		}
+		for _, change := range snapshot.Changes {
+			if change.Kind != firestore.DocumentModified {
+				continue
+			}
+			_, err = s.Sync(ctx, source, change.Doc.Ref.ID, change.Doc.Data())
+			if err != nil {
+				if s.LogError == nil {
+					return err
+				}
+				s.LogError(ctx, "cannot sync "+source+"/"+change.Doc.Ref.ID+": "+err.Error())
+			}
+		}
+	}
+}
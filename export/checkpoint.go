@@ -0,0 +1,175 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type Checkpoint struct {
+	Id        string        `json:"id,omitempty" firestore:"id,omitempty"`
+	Values    []interface{} `json:"values,omitempty" firestore:"values,omitempty"`
+	Count     int64         `json:"count,omitempty" firestore:"count,omitempty"`
+	Bytes     int64         `json:"bytes,omitempty" firestore:"bytes,omitempty"`
+	Completed bool          `json:"completed,omitempty" firestore:"completed,omitempty"`
+	UpdatedAt time.Time     `json:"updatedAt,omitempty" firestore:"updatedAt,omitempty"`
+}
+
+type CheckpointStore interface {
+	Load(ctx context.Context, key string) (*Checkpoint, error)
+	Save(ctx context.Context, key string, checkpoint Checkpoint) error
+	Delete(ctx context.Context, key string) error
+}
+
+type FirestoreCheckpointStore struct {
+	Collection *firestore.CollectionRef
+}
+
+func NewCheckpointStore(client *firestore.Client, collectionName string) *FirestoreCheckpointStore {
+	return &FirestoreCheckpointStore{Collection: client.Collection(collectionName)}
+}
+func (s *FirestoreCheckpointStore) Load(ctx context.Context, key string) (*Checkpoint, error) {
+	doc, err := s.Collection.Doc(key).Get(ctx)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), " not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var checkpoint Checkpoint
+	err = doc.DataTo(&checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+func (s *FirestoreCheckpointStore) Save(ctx context.Context, key string, checkpoint Checkpoint) error {
+	_, err := s.Collection.Doc(key).Set(ctx, checkpoint)
+	return err
+}
+func (s *FirestoreCheckpointStore) Delete(ctx context.Context, key string) error {
+	_, err := s.Collection.Doc(key).Delete(ctx)
+	return err
+}
+
+// ExportWithCheckpoint exports the documents ordered by the orderBy fields (firestore names) then by document id.
+// It saves a checkpoint to the store every interval documents and when the export completes. The checkpoint keeps the number of bytes written,
+// so that the lines written after the last checkpoint of a failed export can be removed: on resume, Truncate is called with Checkpoint.Bytes,
+// or the caller must truncate the output to Checkpoint.Bytes if Truncate is nil. Write must append to the output, and must not buffer the lines.
+// A completed export is not run again, delete the checkpoint of the key to export again.
+// GetIterator cannot be resumed, so it is not used; set GetQuery to filter the documents.
+func (s *Exporter[T]) ExportWithCheckpoint(ctx context.Context, store CheckpointStore, key string, interval int64, orderBy ...string) (int64, error) {
+	collection, err := s.collection(ctx)
+	if err != nil {
+		s.Close()
+		return 0, err
+	}
+	q := collection.Query
+	if s.GetQuery != nil {
+		q = s.GetQuery(ctx, collection)
+	}
+	for _, field := range orderBy {
+		q = q.OrderBy(field, firestore.Asc)
+	}
+	q = q.OrderBy(firestore.DocumentID, firestore.Asc)
+	checkpoint, err := store.Load(ctx, key)
+	if err != nil {
+		s.Close()
+		return 0, err
+	}
+	var i int64
+	var bytes int64
+	if checkpoint != nil && checkpoint.Completed {
+		s.Close()
+		return checkpoint.Count, nil
+	}
+	if checkpoint != nil && len(checkpoint.Id) > 0 {
+		if s.Truncate != nil {
+			if err = s.Truncate(checkpoint.Bytes); err != nil {
+				s.Close()
+				return 0, err
+			}
+		}
+		values := make([]interface{}, 0, len(checkpoint.Values)+1)
+		values = append(values, checkpoint.Values...)
+		values = append(values, checkpoint.Id)
+		q = q.StartAfter(values...)
+		i = checkpoint.Count
+		bytes = checkpoint.Bytes
+		// the header was written before the checkpoint
+		s.headerWritten = true
+	}
+	if interval <= 0 {
+		interval = 1000
+	}
+	defer s.Close()
+	write := func(p []byte) (int, error) {
+		n, err := s.Write(p)
+		bytes = bytes + int64(n)
+		return n, err
+	}
+	last := Checkpoint{}
+	if checkpoint != nil {
+		last = *checkpoint
+	}
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, er1 := iter.Next()
+		if errors.Is(er1, iterator.Done) {
+			break
+		}
+		if er1 != nil {
+			return i, er1
+		}
+		var obj T
+		er2 := doc.DataTo(&obj)
+		if er2 != nil {
+			return i, er2
+		}
+		BindCommonFields(&obj, doc, s.IdIndex, s.CreateTimeIndex, s.UpdateTimeIndex)
+		er3 := s.TransformAndWrite(ctx, write, &obj)
+		if er3 != nil {
+			return i, er3
+		}
+		i = i + 1
+		values := make([]interface{}, len(orderBy))
+		for k, field := range orderBy {
+			values[k], _ = doc.DataAt(field)
+		}
+		last = Checkpoint{Id: doc.Ref.ID, Values: values, Count: i, Bytes: bytes}
+		if i%interval == 0 {
+			last.UpdatedAt = time.Now()
+			er4 := store.Save(ctx, key, last)
+			if er4 != nil {
+				return i, er4
+			}
+		}
+	}
+	// an empty export has the header only
+	if er5 := s.writeHeader(write); er5 != nil {
+		return i, er5
+	}
+	last.Count = i
+	last.Bytes = bytes
+	last.Completed = true
+	last.UpdatedAt = time.Now()
+	return i, store.Save(ctx, key, last)
+}
+
+// NewTruncate returns the Truncate function of an export to a file, it truncates the file to size and moves the offset to its end.
+func NewTruncate(file *os.File) func(size int64) error {
+	return func(size int64) error {
+		if err := file.Truncate(size); err != nil {
+			return err
+		}
+		_, err := file.Seek(size, io.SeekStart)
+		return err
+	}
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"reflect"
+
+	"cloud.google.com/go/firestore"
+)
+
+type CSVFormatter[T any] struct {
+	Columns []Column
+	Comma   rune
+}
+
+// NewCSVFormatter creates the formatter of the given columns (csv or json names), or all columns if names is empty.
+// It panics if a "length" tag is not a positive number.
+func NewCSVFormatter[T any](names ...string) *CSVFormatter[T] {
+	var t T
+	columns, err := BuildColumns(reflect.TypeOf(t), names...)
+	if err != nil {
+		panic(err)
+	}
+	return &CSVFormatter[T]{Columns: columns, Comma: ','}
+}
+func (c *CSVFormatter[T]) Header() (string, error) {
+	record := make([]string, len(c.Columns))
+	for i, column := range c.Columns {
+		record[i] = column.Name
+	}
+	return c.format(record)
+}
+
+// Format returns the csv line of the model, it returns the error of the csv writer, such as an invalid Comma.
+func (c *CSVFormatter[T]) Format(ctx context.Context, model *T) (string, error) {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	record := make([]string, len(c.Columns))
+	for i, column := range c.Columns {
+		record[i] = FormatValue(v.Field(column.Index))
+	}
+	return c.format(record)
+}
+func (c *CSVFormatter[T]) format(record []string) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if c.Comma != 0 {
+		w.Comma = c.Comma
+	}
+	if err := w.Write(record); err != nil {
+		return "", err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// NewCSVExporter exports to w with a header row. columns are csv or json names, all columns are exported if it is empty.
+// The header is written when the export has no line, and it is not written again when ExportWithCheckpoint resumes.
+// It panics if the header cannot be formatted, see NewCSVFormatter.
+func NewCSVExporter[T any](collection *firestore.CollectionRef,
+	getIterator func(context.Context, *firestore.CollectionRef) *firestore.DocumentIterator,
+	w io.Writer,
+	columns []string,
+	options ...string,
+) *Exporter[T] {
+	formatter := NewCSVFormatter[T](columns...)
+	header, err := formatter.Header()
+	if err != nil {
+		panic(err)
+	}
+	exporter := NewExporter[T](collection, getIterator, nil, w.Write, nil, options...)
+	exporter.Format = formatter.Format
+	exporter.Header = []byte(header)
+	exporter.Close = func() error {
+		if err := exporter.writeHeader(w.Write); err != nil {
+			return err
+		}
+		return NewClose(w)()
+	}
+	return exporter
+}
@@ -6,6 +6,9 @@ import (
 	"errors"
 	"google.golang.org/api/iterator"
 	"reflect"
+	"sync"
+
+	f "github.com/core-go/firestore"
 )
 
 func NewExportAdapter[T any](collection *firestore.CollectionRef,
@@ -59,8 +62,17 @@ func NewExporter[T any](collection *firestore.CollectionRef,
 }
 
 type Exporter[T any] struct {
-	Collection      *firestore.CollectionRef
-	Transform       func(context.Context, *T) string
+	Collection *firestore.CollectionRef
+	Transform  func(context.Context, *T) string
+	Format     func(context.Context, *T) (string, error)
+	GetQuery   func(context.Context, *firestore.CollectionRef) firestore.Query
+	// Header is written before the first line
+	Header []byte
+	// Truncate truncates the output to the size saved in the checkpoint when ExportWithCheckpoint resumes, see NewTruncate
+	Truncate        func(size int64) error
+	headerWritten   bool
+	resolveClient   f.ClientResolver
+	resolve         f.CollectionResolver
 	GetIterator     func(context.Context, *firestore.CollectionRef) *firestore.DocumentIterator
 	Write           func(p []byte) (n int, err error)
 	Close           func() error
@@ -69,8 +81,29 @@ type Exporter[T any] struct {
 	UpdateTimeIndex int
 }
 
+// WithResolver makes the exporter resolve the client and the collection for each export, such as the client of the database in the context.
+// If collectionName contains {tenantId}, it is replaced by the tenant id of the context.
+func (s *Exporter[T]) WithResolver(resolveClient f.ClientResolver, collectionName string) *Exporter[T] {
+	s.Collection = nil
+	s.resolveClient = resolveClient
+	s.resolve = f.NewCollectionResolver(resolveClient, collectionName)
+	return s
+}
+
+func (s *Exporter[T]) collection(ctx context.Context) (*firestore.CollectionRef, error) {
+	if s.resolve == nil {
+		return s.Collection, nil
+	}
+	return s.resolve(ctx)
+}
+
 func (s *Exporter[T]) Export(ctx context.Context) (int64, error) {
-	iter := s.GetIterator(ctx, s.Collection)
+	collection, err := s.collection(ctx)
+	if err != nil {
+		s.Close()
+		return 0, err
+	}
+	iter := s.GetIterator(ctx, collection)
 	return s.ScanAndWrite(ctx, iter)
 }
 
@@ -101,8 +134,52 @@ func (s *Exporter[T]) ScanAndWrite(ctx context.Context, iter *firestore.Document
 	return i, nil
 }
 
+// TransformAndWrite writes the line of the model, after the Header for the first line. If Format is set, it is used instead of Transform and its error stops the export.
 func (s *Exporter[T]) TransformAndWrite(ctx context.Context, write func(p []byte) (n int, err error), model *T) error {
+	if err := s.writeHeader(write); err != nil {
+		return err
+	}
+	if s.Format != nil {
+		line, err := s.Format(ctx, model)
+		if err != nil {
+			return err
+		}
+		_, err = write([]byte(line))
+		return err
+	}
 	line := s.Transform(ctx, model)
 	_, er := write([]byte(line))
 	return er
 }
+
+func (s *Exporter[T]) writeHeader(write func(p []byte) (n int, err error)) error {
+	if s.headerWritten || len(s.Header) == 0 {
+		return nil
+	}
+	s.headerWritten = true
+	_, err := write(s.Header)
+	return err
+}
+
+// ExportPartitions scans the collection in partitions with at most workers goroutines. The lines are written one at a time, not in document order.
+// client is the client of the collection, it is resolved from the context instead if the exporter has a resolver.
+func (s *Exporter[T]) ExportPartitions(ctx context.Context, client *firestore.Client, partitions int, workers int, progress func(f.ScanProgress)) (int64, error) {
+	defer s.Close()
+	if s.resolveClient != nil {
+		c, err := s.resolveClient(ctx)
+		if err != nil {
+			return 0, err
+		}
+		client = c
+	}
+	collection, err := s.collection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var mu sync.Mutex
+	return f.Scan[T](ctx, client, collection, partitions, workers, s.IdIndex, s.CreateTimeIndex, s.UpdateTimeIndex, nil, func(ctx context.Context, model *T) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return s.TransformAndWrite(ctx, s.Write, model)
+	}, progress)
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"cloud.google.com/go/firestore"
+)
+
+type FixedWidthFormatter[T any] struct {
+	Columns []Column
+}
+
+// NewFixedWidthFormatter creates the formatter of the fields which have the "length" tag. Strings are left aligned, other values are right aligned.
+// It panics if a "length" tag is not a positive number.
+func NewFixedWidthFormatter[T any]() *FixedWidthFormatter[T] {
+	var t T
+	all, err := BuildColumns(reflect.TypeOf(t))
+	if err != nil {
+		panic(err)
+	}
+	columns := make([]Column, 0, len(all))
+	for _, c := range all {
+		if c.Length > 0 {
+			columns = append(columns, c)
+		}
+	}
+	return &FixedWidthFormatter[T]{Columns: columns}
+}
+func (c *FixedWidthFormatter[T]) Transform(ctx context.Context, model *T) string {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	var sb strings.Builder
+	for _, column := range c.Columns {
+		fv := v.Field(column.Index)
+		s := FormatValue(fv)
+		if utf8.RuneCountInString(s) > column.Length {
+			s = string([]rune(s)[:column.Length])
+		}
+		pad := strings.Repeat(" ", column.Length-utf8.RuneCountInString(s))
+		if reflect.Indirect(fv).Kind() == reflect.String {
+			sb.WriteString(s)
+			sb.WriteString(pad)
+		} else {
+			sb.WriteString(pad)
+			sb.WriteString(s)
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// NewFixedWidthExporter exports to w in fixed width format, driven by the "length" tag.
+func NewFixedWidthExporter[T any](collection *firestore.CollectionRef,
+	getIterator func(context.Context, *firestore.CollectionRef) *firestore.DocumentIterator,
+	w io.Writer,
+	options ...string,
+) *Exporter[T] {
+	formatter := NewFixedWidthFormatter[T]()
+	return NewExporter[T](collection, getIterator, formatter.Transform, w.Write, NewClose(w), options...)
+}
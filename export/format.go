@@ -0,0 +1,119 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Column struct {
+	Index  int
+	Name   string
+	Length int
+}
+
+// BuildColumns builds the columns from the "csv" tag, then the "json" tag, then the field name. Unexported fields are skipped.
+// Fields tagged "-" are skipped too, unless they have a "length" tag, then the column is named by the field name.
+// If names is not empty, only these columns are built, in the same order. It returns an error if a "length" tag is not a positive number.
+func BuildColumns(modelType reflect.Type, names ...string) ([]Column, error) {
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	all := make([]Column, 0)
+	numField := modelType.NumField()
+	for i := 0; i < numField; i++ {
+		field := modelType.Field(i)
+		if len(field.PkgPath) > 0 {
+			// unexported fields cannot be read by reflection
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("csv"); ok {
+			name = strings.Split(tag, ",")[0]
+		} else if tag, ok := field.Tag.Lookup("json"); ok {
+			name = strings.Split(tag, ",")[0]
+		}
+		length := 0
+		if tag, ok := field.Tag.Lookup("length"); ok {
+			n, err := strconv.Atoi(tag)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid length tag %q of field %s", tag, field.Name)
+			}
+			length = n
+		}
+		if name == "-" || len(name) == 0 {
+			if length == 0 {
+				continue
+			}
+			name = field.Name
+		}
+		all = append(all, Column{Index: i, Name: name, Length: length})
+	}
+	if len(names) == 0 {
+		return all, nil
+	}
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		for _, c := range all {
+			if c.Name == name {
+				columns = append(columns, c)
+				break
+			}
+		}
+	}
+	return columns, nil
+}
+
+func FormatValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return ""
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	default:
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+// NewClose closes the writer: it flushes the writer if it has Flush() error, then closes it if it is an io.Closer.
+func NewClose(w io.Writer) func() error {
+	return func() error {
+		if fl, ok := w.(interface{ Flush() error }); ok {
+			if err := fl.Flush(); err != nil {
+				return err
+			}
+		}
+		if cl, ok := w.(io.Closer); ok {
+			return cl.Close()
+		}
+		return nil
+	}
+}
@@ -0,0 +1,29 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"cloud.google.com/go/firestore"
+)
+
+// FormatJSON formats the model as a JSON line.
+func FormatJSON[T any](ctx context.Context, model *T) (string, error) {
+	b, err := json.Marshal(model)
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+// NewJSONLinesExporter exports to w in JSON Lines format. The export stops with the error if a model cannot be marshaled.
+func NewJSONLinesExporter[T any](collection *firestore.CollectionRef,
+	getIterator func(context.Context, *firestore.CollectionRef) *firestore.DocumentIterator,
+	w io.Writer,
+	options ...string,
+) *Exporter[T] {
+	exporter := NewExporter[T](collection, getIterator, nil, w.Write, NewClose(w), options...)
+	exporter.Format = FormatJSON[T]
+	return exporter
+}
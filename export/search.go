@@ -0,0 +1,72 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+)
+
+type SearchExporter[T any, F any] struct {
+	*Exporter[T]
+	ModelType  reflect.Type
+	BuildQuery func(F) ([]f.Query, []string)
+	BuildSort  func(s string, modelType reflect.Type) map[string]firestore.Direction
+	GetSort    func(interface{}) string
+}
+
+func NewSearchExporter[T any, F any](collection *firestore.CollectionRef,
+	buildQuery func(F) ([]f.Query, []string),
+	getSort func(interface{}) string,
+	transform func(context.Context, *T) string,
+	write func(p []byte) (n int, err error),
+	close func() error,
+	options ...string,
+) *SearchExporter[T, F] {
+	return NewSearchExporterWithSort[T, F](collection, buildQuery, f.BuildSort, getSort, transform, write, close, options...)
+}
+func NewSearchExporterWithSort[T any, F any](collection *firestore.CollectionRef,
+	buildQuery func(F) ([]f.Query, []string),
+	buildSort func(string, reflect.Type) map[string]firestore.Direction,
+	getSort func(interface{}) string,
+	transform func(context.Context, *T) string,
+	write func(p []byte) (n int, err error),
+	close func() error,
+	options ...string,
+) *SearchExporter[T, F] {
+	exporter := NewExporter[T](collection, getDocuments, transform, write, close, options...)
+	var t T
+	modelType := reflect.TypeOf(t)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	return &SearchExporter[T, F]{Exporter: exporter, ModelType: modelType, BuildQuery: buildQuery, BuildSort: buildSort, GetSort: getSort}
+}
+
+// ExportByFilter exports all documents matched by the filter, without limit. GeoRadius filters are not supported.
+func (s *SearchExporter[T, F]) ExportByFilter(ctx context.Context, filter F) (int64, error) {
+	query, fields := s.BuildQuery(filter)
+	if geo, _, _ := f.GetGeoRadius(query); geo != nil {
+		s.Close()
+		return 0, fmt.Errorf("%s is not supported for exports", f.GeoRadiusOperator)
+	}
+
+	sort := s.BuildSort(s.GetSort(filter), s.ModelType)
+	collection, err := s.collection(ctx)
+	if err != nil {
+		s.Close()
+		return 0, err
+	}
+	q, err := f.BuildQuerySearch(ctx, collection, query, fields, sort, 0, "")
+	if err != nil {
+		s.Close()
+		return 0, err
+	}
+	return s.ScanAndWrite(ctx, q.Documents(ctx))
+}
+
+func getDocuments(ctx context.Context, collection *firestore.CollectionRef) *firestore.DocumentIterator {
+	return collection.Documents(ctx)
+}
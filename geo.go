@@ -0,0 +1,372 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/genproto/googleapis/type/latlng"
+)
+
+const (
+	GeoRadiusOperator = "geo-radius"
+	GeohashPrecision  = 12
+
+	base32                 = "0123456789bcdefghjkmnpqrstuvwxyz"
+	bitsPerChar            = 5
+	maximumBitsPrecision   = 22 * bitsPerChar
+	earthMeriCircumference = 40007860.0
+	metersPerDegreeLat     = 110574.0
+	earthEqRadius          = 6378137.0
+	earthRadiusKm          = 6371.0
+	e2                     = 0.00669447819799
+	epsilon                = 1e-12
+)
+
+// GeoRadius is a filter value to search documents within Radius kilometers of a point.
+// The firestore name of the filter field must be the geohash field of the collection.
+type GeoRadius struct {
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	Radius    float64 `json:"radius,omitempty"`
+}
+
+func EncodeGeohash(latitude float64, longitude float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+	var sb strings.Builder
+	bits := 0
+	ch := 0
+	even := true
+	for sb.Len() < precision {
+		if even {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if longitude >= mid {
+				ch = ch<<1 | 1
+				lngRange[0] = mid
+			} else {
+				ch = ch << 1
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if latitude >= mid {
+				ch = ch<<1 | 1
+				latRange[0] = mid
+			} else {
+				ch = ch << 1
+				latRange[1] = mid
+			}
+		}
+		even = !even
+		bits++
+		if bits == bitsPerChar {
+			sb.WriteByte(base32[ch])
+			bits = 0
+			ch = 0
+		}
+	}
+	return sb.String()
+}
+
+// DecodeGeohash returns the center of the geohash cell.
+func DecodeGeohash(geohash string) (float64, float64) {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+	even := true
+	for i := 0; i < len(geohash); i++ {
+		v := strings.IndexByte(base32, geohash[i])
+		if v < 0 {
+			break
+		}
+		for b := bitsPerChar - 1; b >= 0; b-- {
+			bit := (v >> uint(b)) & 1
+			if even {
+				mid := (lngRange[0] + lngRange[1]) / 2
+				if bit == 1 {
+					lngRange[0] = mid
+				} else {
+					lngRange[1] = mid
+				}
+			} else {
+				mid := (latRange[0] + latRange[1]) / 2
+				if bit == 1 {
+					latRange[0] = mid
+				} else {
+					latRange[1] = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return (latRange[0] + latRange[1]) / 2, (lngRange[0] + lngRange[1]) / 2
+}
+
+// Distance returns the distance in kilometers between two points.
+func Distance(lat1 float64, lng1 float64, lat2 float64, lng2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// GeohashQueryBounds returns the [start, end] geohash ranges which cover the circle of radius meters around a point.
+func GeohashQueryBounds(latitude float64, longitude float64, radius float64) [][2]string {
+	queryBits := boundingBoxBits(latitude, longitude, radius)
+	if queryBits < 1 {
+		queryBits = 1
+	}
+	precision := int(math.Ceil(float64(queryBits) / bitsPerChar))
+	coordinates := boundingBoxCoordinates(latitude, longitude, radius)
+	bounds := make([][2]string, 0, len(coordinates))
+	for _, c := range coordinates {
+		b := geohashQuery(EncodeGeohash(c[0], c[1], precision), queryBits)
+		exist := false
+		for _, o := range bounds {
+			if o == b {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			bounds = append(bounds, b)
+		}
+	}
+	return bounds
+}
+
+func geohashQuery(geohash string, bits int) [2]string {
+	precision := int(math.Ceil(float64(bits) / bitsPerChar))
+	if len(geohash) < precision {
+		return [2]string{geohash, geohash + "~"}
+	}
+	hash := geohash[:precision]
+	base := hash[:len(hash)-1]
+	lastValue := strings.IndexByte(base32, hash[len(hash)-1])
+	significantBits := bits - len(base)*bitsPerChar
+	unusedBits := uint(bitsPerChar - significantBits)
+	startValue := (lastValue >> unusedBits) << unusedBits
+	endValue := startValue + (1 << unusedBits)
+	if endValue > 31 {
+		return [2]string{base + string(base32[startValue]), base + "~"}
+	}
+	return [2]string{base + string(base32[startValue]), base + string(base32[endValue])}
+}
+func boundingBoxBits(latitude float64, longitude float64, size float64) int {
+	latDelta := size / metersPerDegreeLat
+	latNorth := math.Min(90, latitude+latDelta)
+	latSouth := math.Max(-90, latitude-latDelta)
+	bitsLat := int(math.Floor(latitudeBitsForResolution(size))) * 2
+	bitsLngNorth := int(math.Floor(longitudeBitsForResolution(size, latNorth)))*2 - 1
+	bitsLngSouth := int(math.Floor(longitudeBitsForResolution(size, latSouth)))*2 - 1
+	bits := maximumBitsPrecision
+	for _, b := range []int{bitsLat, bitsLngNorth, bitsLngSouth} {
+		if b < bits {
+			bits = b
+		}
+	}
+	return bits
+}
+func boundingBoxCoordinates(latitude float64, longitude float64, radius float64) [][2]float64 {
+	latDegrees := radius / metersPerDegreeLat
+	latNorth := math.Min(90, latitude+latDegrees)
+	latSouth := math.Max(-90, latitude-latDegrees)
+	lngDegs := math.Max(metersToLongitudeDegrees(radius, latNorth), metersToLongitudeDegrees(radius, latSouth))
+	return [][2]float64{
+		{latitude, longitude},
+		{latitude, wrapLongitude(longitude - lngDegs)},
+		{latitude, wrapLongitude(longitude + lngDegs)},
+		{latNorth, longitude},
+		{latNorth, wrapLongitude(longitude - lngDegs)},
+		{latNorth, wrapLongitude(longitude + lngDegs)},
+		{latSouth, longitude},
+		{latSouth, wrapLongitude(longitude - lngDegs)},
+		{latSouth, wrapLongitude(longitude + lngDegs)},
+	}
+}
+func metersToLongitudeDegrees(distance float64, latitude float64) float64 {
+	radians := toRadians(latitude)
+	num := math.Cos(radians) * earthEqRadius * math.Pi / 180
+	denom := 1 / math.Sqrt(1-e2*math.Sin(radians)*math.Sin(radians))
+	deltaDeg := num * denom
+	if deltaDeg < epsilon {
+		if distance > 0 {
+			return 360
+		}
+		return 0
+	}
+	return math.Min(360, distance/deltaDeg)
+}
+func longitudeBitsForResolution(resolution float64, latitude float64) float64 {
+	degs := metersToLongitudeDegrees(resolution, latitude)
+	if math.Abs(degs) > 0.000001 {
+		return math.Max(1, math.Log2(360/degs))
+	}
+	return 1
+}
+func latitudeBitsForResolution(resolution float64) float64 {
+	return math.Min(math.Log2(earthMeriCircumference/2/resolution), maximumBitsPrecision)
+}
+func wrapLongitude(longitude float64) float64 {
+	if longitude <= 180 && longitude >= -180 {
+		return longitude
+	}
+	adjusted := longitude + 180
+	if adjusted > 0 {
+		return math.Mod(adjusted, 360) - 180
+	}
+	return 180 - math.Mod(-adjusted, 360)
+}
+func toRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// SetGeohash sets the geohash field from the location field, which must be *latlng.LatLng.
+func SetGeohash(model interface{}, locationIndex int, geohashIndex int) {
+	rv := reflect.Indirect(reflect.ValueOf(model))
+	location, _ := rv.Field(locationIndex).Interface().(*latlng.LatLng)
+	hv := rv.Field(geohashIndex)
+	if location == nil {
+		hv.SetString("")
+		return
+	}
+	hv.SetString(EncodeGeohash(location.Latitude, location.Longitude, GeohashPrecision))
+}
+
+// GetGeohash returns the geohash of a location, which is *latlng.LatLng or a map with latitude and longitude, and "" for nil.
+func GetGeohash(location interface{}) (string, error) {
+	switch v := location.(type) {
+	case nil:
+		return "", nil
+	case *latlng.LatLng:
+		if v == nil {
+			return "", nil
+		}
+		return EncodeGeohash(v.Latitude, v.Longitude, GeohashPrecision), nil
+	case map[string]interface{}:
+		lat, ok1 := v["latitude"].(float64)
+		lng, ok2 := v["longitude"].(float64)
+		if ok1 && ok2 {
+			return EncodeGeohash(lat, lng, GeohashPrecision), nil
+		}
+	}
+	return "", fmt.Errorf("cannot get geohash of location %v", location)
+}
+
+// GetGeoRadius returns the first GeoRadius filter of the queries, its field path, and the other queries.
+func GetGeoRadius(queries []Query) (*GeoRadius, string, []Query) {
+	for i, q := range queries {
+		if q.Operator != GeoRadiusOperator {
+			continue
+		}
+		var geo GeoRadius
+		switch v := q.Value.(type) {
+		case GeoRadius:
+			geo = v
+		case *GeoRadius:
+			geo = *v
+		default:
+			continue
+		}
+		others := make([]Query, 0, len(queries)-1)
+		others = append(others, queries[:i]...)
+		others = append(others, queries[i+1:]...)
+		return &geo, q.Path, others
+	}
+	return nil, "", queries
+}
+
+// BuildGeoSearchResult runs the geohash range queries in parallel, keeps the documents within the radius
+// and appends them to results sorted by distance.
+// The distance is measured to the center of the stored geohash, which is within centimeters at GeohashPrecision.
+func BuildGeoSearchResult(ctx context.Context, collection *firestore.CollectionRef, results interface{}, geo GeoRadius, path string, queries []Query, fields []string, limit int64, idIndex int, createdTimeIndex int, updatedTimeIndex int) error {
+	if len(fields) > 0 {
+		exist := false
+		for _, field := range fields {
+			if field == path {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			// the fields are copied, so that the slice of the caller is not changed
+			fields = append(append(make([]string, 0, len(fields)+1), fields...), path)
+		}
+	}
+	type hit struct {
+		doc      *firestore.DocumentSnapshot
+		distance float64
+	}
+	bounds := GeohashQueryBounds(geo.Latitude, geo.Longitude, geo.Radius*1000)
+	hits := make(map[string]hit)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var er0 error
+	for _, b := range bounds {
+		wg.Add(1)
+		go func(start string, end string) {
+			defer wg.Done()
+			q := collection.OrderBy(path, firestore.Asc).StartAt(start).EndAt(end)
+			for _, p := range queries {
+				q = q.Where(p.Path, p.Operator, p.Value)
+			}
+			if len(fields) > 0 {
+				q = q.Select(fields...)
+			}
+			docs, er1 := q.Documents(ctx).GetAll()
+			mu.Lock()
+			defer mu.Unlock()
+			if er1 != nil {
+				if er0 == nil {
+					er0 = er1
+				}
+				return
+			}
+			for _, doc := range docs {
+				v, er2 := doc.DataAt(path)
+				if er2 != nil {
+					continue
+				}
+				geohash, ok := v.(string)
+				if !ok || len(geohash) == 0 {
+					continue
+				}
+				lat, lng := DecodeGeohash(geohash)
+				distance := Distance(geo.Latitude, geo.Longitude, lat, lng)
+				if distance <= geo.Radius {
+					hits[doc.Ref.ID] = hit{doc: doc, distance: distance}
+				}
+			}
+		}(b[0], b[1])
+	}
+	wg.Wait()
+	if er0 != nil {
+		return er0
+	}
+	list := make([]hit, 0, len(hits))
+	for _, h := range hits {
+		list = append(list, h)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].distance < list[j].distance
+	})
+	if limit > 0 && int64(len(list)) > limit {
+		list = list[:limit]
+	}
+	modelType := reflect.TypeOf(results).Elem().Elem()
+	for _, h := range list {
+		result := reflect.New(modelType).Interface()
+		er3 := h.doc.DataTo(result)
+		if er3 != nil {
+			return er3
+		}
+		BindCommonFields(result, h.doc, idIndex, createdTimeIndex, updatedTimeIndex)
+		results = appendToArray(results, result)
+	}
+	return nil
+}
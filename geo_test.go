@@ -0,0 +1,100 @@
+package firestore
+
+import (
+	"math"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/type/latlng"
+)
+
+func TestEncodeGeohash(t *testing.T) {
+	tests := []struct {
+		latitude  float64
+		longitude float64
+		precision int
+		geohash   string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{37.7853074, -122.4054274, 10, "9q8yywe56g"},
+		{-90, -180, 10, "0000000000"},
+		{90, 180, 10, "zzzzzzzzzz"},
+		{-90, 180, 10, "pbpbpbpbpb"},
+		{90, -180, 10, "bpbpbpbpbp"},
+	}
+	for _, test := range tests {
+		geohash := EncodeGeohash(test.latitude, test.longitude, test.precision)
+		if geohash != test.geohash {
+			t.Errorf("EncodeGeohash(%v, %v, %d) = %s, expected %s", test.latitude, test.longitude, test.precision, geohash, test.geohash)
+		}
+	}
+}
+
+func TestDecodeGeohash(t *testing.T) {
+	latitude, longitude := DecodeGeohash("u4pruydqqvj")
+	if math.Abs(latitude-57.64911) > 1e-5 || math.Abs(longitude-10.40744) > 1e-5 {
+		t.Errorf("DecodeGeohash(u4pruydqqvj) = %v, %v, expected 57.64911, 10.40744", latitude, longitude)
+	}
+	latitude, longitude = DecodeGeohash(EncodeGeohash(-33.8688, 151.2093, GeohashPrecision))
+	if Distance(latitude, longitude, -33.8688, 151.2093) > 0.001 {
+		t.Errorf("DecodeGeohash does not return the encoded point: %v, %v", latitude, longitude)
+	}
+}
+
+func TestGeohashQueryBounds(t *testing.T) {
+	centers := [][2]float64{{57.64911, 10.40744}, {37.7853074, -122.4054274}, {0, 179.99}, {-33.8688, 151.2093}}
+	radiuses := []float64{100, 1000, 50000}
+	for _, c := range centers {
+		for _, radius := range radiuses {
+			bounds := GeohashQueryBounds(c[0], c[1], radius)
+			if len(bounds) == 0 {
+				t.Fatalf("GeohashQueryBounds(%v, %v, %v) returns no bounds", c[0], c[1], radius)
+			}
+			// points on a circle slightly inside the radius must be within one of the bounds
+			for angle := 0.0; angle < 360; angle += 15 {
+				latitude, longitude := destination(c[0], c[1], radius*0.99, angle)
+				geohash := EncodeGeohash(latitude, longitude, GeohashPrecision)
+				if !inBounds(geohash, bounds) {
+					t.Errorf("GeohashQueryBounds(%v, %v, %v) does not cover %v, %v (%s): %v", c[0], c[1], radius, latitude, longitude, geohash, bounds)
+				}
+			}
+		}
+	}
+}
+
+func TestGetGeohash(t *testing.T) {
+	geohash, err := GetGeohash(&latlng.LatLng{Latitude: 57.64911, Longitude: 10.40744})
+	if err != nil || geohash != EncodeGeohash(57.64911, 10.40744, GeohashPrecision) {
+		t.Errorf("GetGeohash(*latlng.LatLng) = %s, %v", geohash, err)
+	}
+	geohash, err = GetGeohash(map[string]interface{}{"latitude": 57.64911, "longitude": 10.40744})
+	if err != nil || geohash != EncodeGeohash(57.64911, 10.40744, GeohashPrecision) {
+		t.Errorf("GetGeohash(map) = %s, %v", geohash, err)
+	}
+	geohash, err = GetGeohash(nil)
+	if err != nil || geohash != "" {
+		t.Errorf("GetGeohash(nil) = %s, %v", geohash, err)
+	}
+	if _, err = GetGeohash("u4pruydqqvj"); err == nil {
+		t.Errorf("GetGeohash(string) must return an error")
+	}
+}
+
+func inBounds(geohash string, bounds [][2]string) bool {
+	for _, b := range bounds {
+		if geohash >= b[0] && geohash <= b[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// destination returns the point at distance meters from a point, in the direction of bearing degrees.
+func destination(latitude float64, longitude float64, distance float64, bearing float64) (float64, float64) {
+	d := distance / 1000 / earthRadiusKm
+	lat1 := toRadians(latitude)
+	lng1 := toRadians(longitude)
+	b := toRadians(bearing)
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lng2 := lng1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return lat2 * 180 / math.Pi, wrapLongitude(lng2 * 180 / math.Pi)
+}
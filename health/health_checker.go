@@ -3,8 +3,18 @@ package health
 import (
 	"cloud.google.com/go/firestore"
 	"context"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	"google.golang.org/api/transport"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPath    = "_health/_health"
+	DefaultTimeout = 4 * time.Second
 )
 
 type HealthChecker struct {
@@ -12,10 +22,13 @@ type HealthChecker struct {
 	projectId   string
 	opts        []option.ClientOption
 	credentials []byte
+	client      *firestore.Client
+	path        string
+	timeout     time.Duration
 }
 
 func NewFirestoreHealthChecker(name string, projectId string, opts ...option.ClientOption) *HealthChecker {
-	return &HealthChecker{projectId: projectId, name: name, opts: opts}
+	return &HealthChecker{projectId: projectId, name: name, opts: opts, path: DefaultPath, timeout: DefaultTimeout}
 }
 
 func NewHealthCheckerWithProjectId(projectId string, opts ...option.ClientOption) *HealthChecker {
@@ -38,20 +51,113 @@ func NewHealthChecker(ctx context.Context, credentials []byte, options ...string
 	}
 	return NewFirestoreHealthChecker(name, creds.ProjectID, opts)
 }
+
+// NewHealthCheckerWithClient reuses the client instead of dialing a new client for each check.
+// Options are the name and the path to read: a collection path runs a Limit(1) query, a document path gets the document.
+func NewHealthCheckerWithClient(client *firestore.Client, timeout time.Duration, options ...string) *HealthChecker {
+	name := "firestore"
+	if len(options) > 0 && len(options[0]) > 0 {
+		name = options[0]
+	}
+	path := DefaultPath
+	if len(options) > 1 && len(options[1]) > 0 {
+		path = options[1]
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &HealthChecker{name: name, client: client, path: path, timeout: timeout}
+}
+
+// WithPath sets the collection or document path to read and the timeout of the check.
+func (s *HealthChecker) WithPath(path string, timeout time.Duration) *HealthChecker {
+	if len(path) > 0 {
+		s.path = path
+	}
+	if timeout > 0 {
+		s.timeout = timeout
+	}
+	return s
+}
+
 func (s HealthChecker) Name() string {
 	return s.name
 }
 
 func (s HealthChecker) Check(ctx context.Context) (map[string]interface{}, error) {
 	res := make(map[string]interface{})
-	client, err := firestore.NewClient(ctx, s.projectId, s.opts...)
+	timeout := s.timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	client := s.client
+	if client == nil {
+		c, err := firestore.NewClient(ctx, s.projectId, s.opts...)
+		if err != nil {
+			res["code"] = status.Code(err).String()
+			return res, err
+		}
+		defer c.Close()
+		client = c
+	}
+	path := s.path
+	if len(path) == 0 {
+		path = DefaultPath
+	}
+	start := time.Now()
+	err := read(ctx, client, path)
+	res["latency"] = time.Since(start).Milliseconds()
+	projectId, database := getDatabase(client)
+	if len(projectId) > 0 {
+		res["projectId"] = projectId
+	} else if len(s.projectId) > 0 {
+		res["projectId"] = s.projectId
+	}
+	if len(database) > 0 {
+		res["database"] = database
+	}
 	if err != nil {
+		res["code"] = status.Code(err).String()
+		if ctx.Err() == context.DeadlineExceeded {
+			res["code"] = codes.DeadlineExceeded.String()
+		}
 		return res, err
 	}
-	defer client.Close()
 	return res, nil
 }
 
+func read(ctx context.Context, client *firestore.Client, path string) error {
+	if strings.Count(strings.Trim(path, "/"), "/")%2 == 0 {
+		iter := client.Collection(path).Limit(1).Documents(ctx)
+		defer iter.Stop()
+		_, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		return err
+	}
+	_, err := client.Doc(path).Get(ctx)
+	if err != nil && status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+// getDatabase returns the project id and the database id from the resource name of a document.
+func getDatabase(client *firestore.Client) (string, string) {
+	ref := client.Doc(DefaultPath)
+	if ref == nil {
+		return "", ""
+	}
+	segments := strings.Split(ref.Path, "/")
+	if len(segments) < 4 {
+		return "", ""
+	}
+	return segments[1], segments[3]
+}
+
 func (s *HealthChecker) Build(ctx context.Context, data map[string]interface{}, err error) map[string]interface{} {
 	if err == nil {
 		return data
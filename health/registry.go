@@ -0,0 +1,25 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"github.com/core-go/firestore/client"
+)
+
+// NewDatabaseHealthChecker creates the health checker of a named database of the registry, its name is "firestore-" + the database id.
+func NewDatabaseHealthChecker(ctx context.Context, registry *client.Registry, databaseId string, timeout time.Duration, options ...string) (*HealthChecker, error) {
+	c, err := registry.Get(ctx, databaseId)
+	if err != nil {
+		return nil, err
+	}
+	name := "firestore"
+	if len(databaseId) > 0 {
+		name = name + "-" + databaseId
+	}
+	path := ""
+	if len(options) > 0 {
+		path = options[0]
+	}
+	return NewHealthCheckerWithClient(c, timeout, name, path), nil
+}
@@ -0,0 +1,158 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportCSV reads the CSV with a header row. Columns are mapped to fields by the "csv" tag, then the "json" tag, then the field name, case-insensitive.
+// Unexported fields are skipped, T must be a struct.
+func (s *Importer[T]) ImportCSV(ctx context.Context, r io.Reader) (Result, error) {
+	var res Result
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return res, nil
+		}
+		return res, err
+	}
+	modelType := reflect.TypeOf((*T)(nil)).Elem()
+	if modelType.Kind() != reflect.Struct {
+		return res, fmt.Errorf("%s is not a struct", modelType.String())
+	}
+	indexes := make([]int, len(header))
+	for i, name := range header {
+		indexes[i] = getColumnIndex(modelType, strings.TrimSpace(name))
+	}
+	c := &chunk[T]{}
+	for {
+		record, er1 := reader.Read()
+		if er1 == io.EOF {
+			break
+		}
+		if er1 != nil {
+			var pe *csv.ParseError
+			if errors.As(er1, &pe) {
+				res.Total = res.Total + 1
+				res.Errors = append(res.Errors, LineError{Line: pe.StartLine, Message: pe.Err.Error()})
+				continue
+			}
+			s.flush(ctx, c, &res)
+			return res, er1
+		}
+		// FieldPos is valid after a successful Read only
+		line, _ := reader.FieldPos(0)
+		var model T
+		mv := reflect.ValueOf(&model).Elem()
+		var er2 error
+		for i, value := range record {
+			if i >= len(indexes) || indexes[i] < 0 {
+				continue
+			}
+			if er2 = setValue(mv.Field(indexes[i]), value); er2 != nil {
+				er2 = fmt.Errorf("%s: %s", header[i], er2.Error())
+				break
+			}
+		}
+		if er2 != nil {
+			res.Total = res.Total + 1
+			res.Errors = append(res.Errors, LineError{Line: line, Message: er2.Error()})
+			continue
+		}
+		s.add(ctx, c, &res, line, model)
+	}
+	s.flush(ctx, c, &res)
+	return res, nil
+}
+
+func getColumnIndex(modelType reflect.Type, name string) int {
+	numField := modelType.NumField()
+	for i := 0; i < numField; i++ {
+		field := modelType.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		if tag, ok := field.Tag.Lookup("csv"); ok {
+			if strings.EqualFold(strings.Split(tag, ",")[0], name) {
+				return i
+			}
+			continue
+		}
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if strings.EqualFold(strings.Split(tag, ",")[0], name) {
+				return i
+			}
+			continue
+		}
+		if strings.EqualFold(field.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func setValue(fv reflect.Value, s string) error {
+	if len(s) == 0 {
+		return nil
+	}
+	if fv.Kind() == reflect.Ptr {
+		v := reflect.New(fv.Type().Elem())
+		if err := setValue(v.Elem(), s); err != nil {
+			return err
+		}
+		fv.Set(v)
+		return nil
+	}
+	if _, ok := fv.Interface().(time.Time); ok {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", s)
+			if err != nil {
+				return err
+			}
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(v)
+	default:
+		return json.Unmarshal([]byte(s), fv.Addr().Interface())
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type user struct {
+	Id   string `json:"id" bson:"_id"`
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestImportCSVMalformedLine(t *testing.T) {
+	inputs := []string{
+		"id,name,age\n1,a,10\n2,b\"x,20\n3,c,30\n",
+		"id,name,age\n1,a,10\n2,\"b\"x,20\n3,c,30\n",
+		"id,name,age\n1,a,10\n2,\"b,20\n3,c,30\n",
+	}
+	for _, input := range inputs {
+		var written []user
+		s := NewImporterWithWriter[user](func(ctx context.Context, models []user) (int, error) {
+			written = append(written, models...)
+			return len(models), nil
+		}, 10, nil)
+		res, err := s.ImportCSV(context.Background(), strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(res.Errors) == 0 {
+			t.Fatalf("expected a line error for %q", input)
+		}
+		if res.Errors[0].Line != 3 {
+			t.Errorf("expected the error at line 3, got %d", res.Errors[0].Line)
+		}
+		if len(written) == 0 || written[0].Id != "1" || written[0].Age != 10 {
+			t.Errorf("expected the valid line to be written, got %v", written)
+		}
+		if res.Success != len(written) {
+			t.Errorf("expected success %d, got %d", len(written), res.Success)
+		}
+	}
+}
+
+func TestImportCSVWriteModels(t *testing.T) {
+	s := NewImporterWithWriter[user](nil, 10, nil)
+	s.WriteModels = func(ctx context.Context, models []user) (map[int]string, error) {
+		return map[int]string{1: "document already exists"}, nil
+	}
+	res, err := s.ImportCSV(context.Background(), strings.NewReader("id,name,age\n1,a,10\n2,b,20\n3,c,30\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Total != 3 || res.Success != 2 || len(res.Errors) != 1 || res.Errors[0].Line != 3 {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestImportCSVUnexportedField(t *testing.T) {
+	type account struct {
+		Id     string `json:"id" bson:"_id"`
+		secret string
+	}
+	var written []account
+	s := NewImporterWithWriter[account](func(ctx context.Context, models []account) (int, error) {
+		written = append(written, models...)
+		return len(models), nil
+	}, 10, nil)
+	res, err := s.ImportCSV(context.Background(), strings.NewReader("id,secret\n1,x\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Success != 1 || len(written) != 1 || written[0].secret != "" {
+		t.Errorf("unexpected result %+v, %v", res, written)
+	}
+}
+
+func TestImportCSVPointerType(t *testing.T) {
+	s := NewImporterWithWriter[*user](func(ctx context.Context, models []*user) (int, error) {
+		return len(models), nil
+	}, 10, nil)
+	if _, err := s.ImportCSV(context.Background(), strings.NewReader("id,name\n1,a\n")); err == nil {
+		t.Errorf("expected an error for a pointer type")
+	}
+}
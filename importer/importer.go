@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/core-go/firestore/batch"
+)
+
+const (
+	ModeCreate = "create"
+	ModeUpsert = "upsert"
+	ModeUpdate = "update"
+)
+
+type LineError struct {
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Result struct {
+	Total   int         `json:"total"`
+	Success int         `json:"success"`
+	Errors  []LineError `json:"errors,omitempty"`
+}
+
+type Importer[T any] struct {
+	Write func(ctx context.Context, models []T) (int, error)
+	// WriteModels is used instead of Write if it is set, it returns the messages of the models which are not written, keyed by their indexes.
+	WriteModels func(ctx context.Context, models []T) (map[int]string, error)
+	Validate    func(ctx context.Context, model *T) error
+	BatchSize   int
+	DryRun      bool
+}
+
+// NewImporter writes with batch.BatchCreator (ModeCreate), batch.BatchUpdater (ModeUpdate) or batch.BatchWriter (ModeUpsert, default).
+func NewImporter[T any](client *firestore.Client, collectionName string, mode string, batchSize int, validate func(context.Context, *T) error, opts ...func(*T)) *Importer[T] {
+	switch mode {
+	case ModeCreate:
+		creator := batch.NewBatchCreator[T](client, collectionName, opts...)
+		s := NewImporterWithWriter[T](creator.Write, batchSize, validate)
+		s.WriteModels = func(ctx context.Context, models []T) (map[int]string, error) {
+			existing, err := creator.Create(ctx, models)
+			if err != nil {
+				return nil, err
+			}
+			errs := make(map[int]string)
+			for _, i := range existing {
+				errs[i] = "document already exists"
+			}
+			return errs, nil
+		}
+		return s
+	case ModeUpdate:
+		updater := batch.NewBatchUpdater[T](client, collectionName, opts...)
+		s := NewImporterWithWriter[T](updater.Write, batchSize, validate)
+		s.WriteModels = func(ctx context.Context, models []T) (map[int]string, error) {
+			return update(ctx, updater, models)
+		}
+		return s
+	default:
+		return NewImporterWithWriter[T](batch.NewBatchWriterWithIdName[T](client, collectionName, opts...).Write, batchSize, validate)
+	}
+}
+
+// update updates the existing documents, the models with empty id and the missing documents are reported.
+func update[T any](ctx context.Context, updater *batch.BatchUpdater[T], models []T) (map[int]string, error) {
+	errs := make(map[int]string)
+	positions := make(map[string][]int)
+	list := make([]T, 0, len(models))
+	for i := range models {
+		id, _ := batch.GetValueByIndex(models[i], updater.Idx).(string)
+		if len(id) == 0 {
+			errs[i] = "id is empty"
+			continue
+		}
+		positions[id] = append(positions[id], i)
+		list = append(list, models[i])
+	}
+	missing, conflicts, err := updater.Update(ctx, list)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range missing {
+		for _, i := range positions[id] {
+			errs[i] = "document does not exist"
+		}
+	}
+	for _, id := range conflicts {
+		for _, i := range positions[id] {
+			errs[i] = "version conflict"
+		}
+	}
+	return errs, nil
+}
+func NewImporterWithWriter[T any](write func(context.Context, []T) (int, error), batchSize int, validate func(context.Context, *T) error) *Importer[T] {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &Importer[T]{Write: write, Validate: validate, BatchSize: batchSize}
+}
+
+type chunk[T any] struct {
+	models []T
+	lines  []int
+}
+
+func (s *Importer[T]) add(ctx context.Context, c *chunk[T], res *Result, line int, model T) {
+	res.Total = res.Total + 1
+	if s.Validate != nil {
+		if err := s.Validate(ctx, &model); err != nil {
+			res.Errors = append(res.Errors, LineError{Line: line, Message: err.Error()})
+			return
+		}
+	}
+	c.models = append(c.models, model)
+	c.lines = append(c.lines, line)
+	if len(c.models) >= s.BatchSize {
+		s.flush(ctx, c, res)
+	}
+}
+
+func (s *Importer[T]) flush(ctx context.Context, c *chunk[T], res *Result) {
+	if len(c.models) == 0 {
+		return
+	}
+	if !s.DryRun && s.WriteModels != nil {
+		errs, err := s.WriteModels(ctx, c.models)
+		if err != nil {
+			errs = make(map[int]string, len(c.models))
+			for i := range c.models {
+				errs[i] = err.Error()
+			}
+		}
+		for i, line := range c.lines {
+			if msg, ok := errs[i]; ok {
+				res.Errors = append(res.Errors, LineError{Line: line, Message: msg})
+			}
+		}
+		res.Success = res.Success + len(c.models) - len(errs)
+		c.models = c.models[:0]
+		c.lines = c.lines[:0]
+		return
+	}
+	if !s.DryRun {
+		// the batch writers run in a transaction, so the whole chunk fails together
+		if _, err := s.Write(ctx, c.models); err != nil {
+			for _, line := range c.lines {
+				res.Errors = append(res.Errors, LineError{Line: line, Message: err.Error()})
+			}
+			c.models = c.models[:0]
+			c.lines = c.lines[:0]
+			return
+		}
+	}
+	res.Success = res.Success + len(c.models)
+	c.models = c.models[:0]
+	c.lines = c.lines[:0]
+}
@@ -0,0 +1,34 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// ImportJSONLines reads one JSON object per line, empty lines are skipped.
+func (s *Importer[T]) ImportJSONLines(ctx context.Context, r io.Reader) (Result, error) {
+	var res Result
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	c := &chunk[T]{}
+	line := 0
+	for scanner.Scan() {
+		line = line + 1
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 {
+			continue
+		}
+		var model T
+		if err := json.Unmarshal([]byte(text), &model); err != nil {
+			res.Total = res.Total + 1
+			res.Errors = append(res.Errors, LineError{Line: line, Message: err.Error()})
+			continue
+		}
+		s.add(ctx, c, &res, line, model)
+	}
+	s.flush(ctx, c, &res)
+	return res, scanner.Err()
+}
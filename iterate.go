@@ -0,0 +1,61 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// Iterate decodes the documents of the query one by one, it stops when yield returns false or after the first error.
+// The result has the shape of iter.Seq2[T, error], so it can be used in a range loop since go 1.23.
+func Iterate[T any](ctx context.Context, query firestore.Query, idIndex int, createdTimeIndex int, updatedTimeIndex int, mp func(*T)) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		iter := query.Documents(ctx)
+		defer iter.Stop()
+		for {
+			doc, er1 := iter.Next()
+			if er1 == iterator.Done {
+				return
+			}
+			var obj T
+			if er1 != nil {
+				yield(obj, er1)
+				return
+			}
+			er2 := doc.DataTo(&obj)
+			if er2 != nil {
+				yield(obj, er2)
+				return
+			}
+			BindCommonFields(&obj, doc, idIndex, createdTimeIndex, updatedTimeIndex)
+			if mp != nil {
+				mp(&obj)
+			}
+			if !yield(obj, nil) {
+				return
+			}
+		}
+	}
+}
+
+// IterateSearch iterates all documents matched by the search query, without limit. GeoRadius filters are not supported.
+func IterateSearch[T any](ctx context.Context, collection *firestore.CollectionRef, query []Query, fields []string, sort map[string]firestore.Direction, idIndex int, createdTimeIndex int, updatedTimeIndex int, mp func(*T)) func(yield func(T, error) bool) {
+	if geo, _, _ := GetGeoRadius(query); geo != nil {
+		return IterateError[T](fmt.Errorf("%s is not supported for iterations", GeoRadiusOperator))
+	}
+	q, err := BuildQuerySearch(ctx, collection, query, fields, sort, 0, "")
+	if err != nil {
+		return IterateError[T](err)
+	}
+	return Iterate[T](ctx, q, idIndex, createdTimeIndex, updatedTimeIndex, mp)
+}
+
+// IterateError returns an iterator which yields the error only.
+func IterateError[T any](err error) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		var obj T
+		yield(obj, err)
+	}
+}
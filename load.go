@@ -29,3 +29,41 @@ func Load(ctx context.Context, collection *firestore.CollectionRef, id string, r
 	er2 := doc.DataTo(res)
 	return true, doc, er2
 }
+
+// LoadMany loads the documents with GetAll in chunks of 500 ids. The result is in the order of ids, with nil for the missing documents,
+// which are also returned in the list of missing ids. Empty ids are skipped: their results are nil, and they are not reported as missing.
+func LoadMany[T any](ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, ids []string, idIndex int, createdTimeIndex int, updatedTimeIndex int, mp func(*T)) ([]*T, []string, error) {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if len(id) > 0 {
+			keys = append(keys, id)
+		}
+	}
+	docs, err := GetAll(ctx, client, collection, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	results := make([]*T, len(ids))
+	missing := make([]string, 0)
+	for i, id := range ids {
+		if len(id) == 0 {
+			continue
+		}
+		doc, ok := docs[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		var obj T
+		err = doc.DataTo(&obj)
+		if err != nil {
+			return nil, nil, err
+		}
+		BindCommonFields(&obj, doc, idIndex, createdTimeIndex, updatedTimeIndex)
+		if mp != nil {
+			mp(&obj)
+		}
+		results[i] = &obj
+	}
+	return results, missing, nil
+}
@@ -0,0 +1,125 @@
+package loader
+
+import (
+	"cloud.google.com/go/firestore"
+	"context"
+	"fmt"
+	"google.golang.org/api/iterator"
+	"reflect"
+)
+
+// MaxIn is the max number of values of an "in" filter.
+const MaxIn = 30
+
+type KeyValue[V any] struct {
+	Id    string `json:"id,omitempty"`
+	Value V      `json:"value,omitempty"`
+}
+
+// ValueLoader loads the selected fields of the documents filtered by document ids, or by the values of the Key field.
+// If V is a struct or a map, the selected fields are decoded into V, else V is the value of the only selected field.
+type ValueLoader[V any] struct {
+	Collection *firestore.CollectionRef
+	Key        string
+	Fields     []string
+}
+
+// NewValueLoader creates the loader, the key is the filter field, or firestore.DocumentID or an empty string to filter by document ids.
+func NewValueLoader[V any](client *firestore.Client, collectionName string, key string, fields ...string) *ValueLoader[V] {
+	if len(fields) == 0 {
+		panic("fields cannot be empty")
+	}
+	if len(key) == 0 {
+		key = firestore.DocumentID
+	}
+	var v V
+	kind := reflect.TypeOf(&v).Elem().Kind()
+	if len(fields) > 1 && kind != reflect.Struct && kind != reflect.Map {
+		panic("V must be a struct or a map to load multiple fields")
+	}
+	return &ValueLoader[V]{Collection: client.Collection(collectionName), Key: key, Fields: fields}
+}
+
+// Map returns the values by document id. If keys is empty, it loads all documents.
+func (l *ValueLoader[V]) Map(ctx context.Context, keys []string) (map[string]V, error) {
+	m := make(map[string]V)
+	err := l.load(ctx, keys, func(id string, v V) {
+		m[id] = v
+	})
+	return m, err
+}
+
+// List returns the values with their document ids. If keys is empty, it loads all documents.
+func (l *ValueLoader[V]) List(ctx context.Context, keys []string) ([]KeyValue[V], error) {
+	list := make([]KeyValue[V], 0)
+	err := l.load(ctx, keys, func(id string, v V) {
+		list = append(list, KeyValue[V]{Id: id, Value: v})
+	})
+	return list, err
+}
+
+func (l *ValueLoader[V]) load(ctx context.Context, keys []string, handle func(string, V)) error {
+	if len(keys) == 0 {
+		return l.query(ctx, l.Collection.Select(l.Fields...), handle)
+	}
+	for start := 0; start < len(keys); start += MaxIn {
+		end := start + MaxIn
+		if end > len(keys) {
+			end = len(keys)
+		}
+		values := make([]interface{}, 0, end-start)
+		for _, key := range keys[start:end] {
+			if l.Key == firestore.DocumentID {
+				values = append(values, l.Collection.Doc(key))
+			} else {
+				values = append(values, key)
+			}
+		}
+		err := l.query(ctx, l.Collection.Select(l.Fields...).Where(l.Key, "in", values), handle)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *ValueLoader[V]) query(ctx context.Context, query firestore.Query, handle func(string, V)) error {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		v, err := l.decode(doc)
+		if err != nil {
+			return err
+		}
+		handle(doc.Ref.ID, v)
+	}
+}
+
+func (l *ValueLoader[V]) decode(doc *firestore.DocumentSnapshot) (V, error) {
+	var v V
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.Kind() == reflect.Struct || rv.Kind() == reflect.Map {
+		err := doc.DataTo(&v)
+		return v, err
+	}
+	data, err := doc.DataAt(l.Fields[0])
+	if err != nil || data == nil {
+		return v, nil
+	}
+	if value, ok := data.(V); ok {
+		return value, nil
+	}
+	dv := reflect.ValueOf(data)
+	if !dv.Type().ConvertibleTo(rv.Type()) || (rv.Kind() == reflect.String && dv.Kind() != reflect.String) {
+		return v, fmt.Errorf("cannot convert %s of document %s to %s", l.Fields[0], doc.Ref.ID, rv.Type().String())
+	}
+	rv.Set(dv.Convert(rv.Type()))
+	return v, nil
+}
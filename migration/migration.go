@@ -0,0 +1,216 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+	"github.com/core-go/firestore/batch"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	CollectionName  = "_migrations"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	DefaultTimeout  = time.Hour
+)
+
+// ErrRunning is returned by Run when the migration is being run by another process.
+var ErrRunning = errors.New("migration is running")
+
+type History struct {
+	Id          string     `json:"id,omitempty" firestore:"-"`
+	Status      string     `json:"status,omitempty" firestore:"status,omitempty"`
+	Count       int64      `json:"count,omitempty" firestore:"count,omitempty"`
+	Error       string     `json:"error,omitempty" firestore:"error,omitempty"`
+	StartedAt   *time.Time `json:"startedAt,omitempty" firestore:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" firestore:"completedAt,omitempty"`
+}
+
+// Migration streams the documents of the source collection, transforms them and saves them into the target collection in chunks.
+// The completed migration ids are recorded in the "_migrations" collection of the target client, so each migration runs once.
+// A running migration is claimed for Timeout, after which it is considered as crashed and can be run again.
+type Migration[S any, D any] struct {
+	Id               string
+	Source           *firestore.CollectionRef
+	Target           *firestore.CollectionRef
+	History          *firestore.CollectionRef
+	Transform        func(context.Context, *S) (*D, error)
+	BatchSize        int
+	Timeout          time.Duration
+	client           *firestore.Client
+	idIndex          int
+	createdTimeIndex int
+	updatedTimeIndex int
+	targetIdIndex    int
+}
+
+// NewMigration creates a migration, options are the created time and updated time field names of S.
+func NewMigration[S any, D any](id string, sourceClient *firestore.Client, sourceCollectionName string, targetClient *firestore.Client, targetCollectionName string, transform func(context.Context, *S) (*D, error), batchSize int, options ...string) *Migration[S, D] {
+	var s S
+	sourceType := reflect.TypeOf(s)
+	if sourceType.Kind() != reflect.Struct {
+		panic("S must be a struct")
+	}
+	idx, _, _ := f.FindIdField(sourceType)
+	if idx < 0 {
+		panic(fmt.Sprintf("%s struct requires id field which has bson tag '_id'", sourceType.Name()))
+	}
+	var d D
+	targetType := reflect.TypeOf(d)
+	if targetType.Kind() != reflect.Struct {
+		panic("D must be a struct")
+	}
+	targetIdx := batch.FindIdField(targetType)
+	if targetIdx < 0 {
+		panic(fmt.Sprintf("%s struct requires id field which has bson tag '_id'", targetType.Name()))
+	}
+	ctIdx := -1
+	if len(options) > 0 && len(options[0]) > 0 {
+		ctIdx, _, _ = f.FindFieldByName(sourceType, options[0])
+	}
+	utIdx := -1
+	if len(options) > 1 && len(options[1]) > 0 {
+		utIdx, _, _ = f.FindFieldByName(sourceType, options[1])
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &Migration[S, D]{
+		Id:               id,
+		Source:           sourceClient.Collection(sourceCollectionName),
+		Target:           targetClient.Collection(targetCollectionName),
+		History:          targetClient.Collection(CollectionName),
+		Transform:        transform,
+		BatchSize:        batchSize,
+		Timeout:          DefaultTimeout,
+		client:           targetClient,
+		idIndex:          idx,
+		createdTimeIndex: ctIdx,
+		updatedTimeIndex: utIdx,
+		targetIdIndex:    targetIdx,
+	}
+}
+
+func (m *Migration[S, D]) Load(ctx context.Context) (*History, error) {
+	doc, err := m.History.Doc(m.Id).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var history History
+	err = doc.DataTo(&history)
+	if err != nil {
+		return nil, err
+	}
+	history.Id = doc.Ref.ID
+	return &history, nil
+}
+
+// Run runs the migration if it was not completed. It returns false if the migration was completed before,
+// and ErrRunning if it is being run by another process.
+// A transform which returns nil skips the document. If the id of the transformed model is empty, the id of the source document is used,
+// so that running a failed migration again overwrites the documents instead of creating duplicates.
+func (m *Migration[S, D]) Run(ctx context.Context) (bool, int64, error) {
+	now := time.Now()
+	var completed *History
+	ref := m.History.Doc(m.Id)
+	err := m.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		completed = nil
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			var history History
+			if err = doc.DataTo(&history); err != nil {
+				return err
+			}
+			if history.Status == StatusCompleted {
+				completed = &history
+				return nil
+			}
+			if history.Status == StatusRunning && history.StartedAt != nil && now.Sub(*history.StartedAt) < m.Timeout {
+				return ErrRunning
+			}
+		}
+		return tx.Set(ref, History{Status: StatusRunning, StartedAt: &now})
+	})
+	if err != nil {
+		return false, 0, err
+	}
+	if completed != nil {
+		return false, completed.Count, nil
+	}
+	count, err := m.migrate(ctx)
+	completedAt := time.Now()
+	if err != nil {
+		if _, er2 := m.History.Doc(m.Id).Set(ctx, History{Status: StatusFailed, Count: count, Error: err.Error(), StartedAt: &now, CompletedAt: &completedAt}); er2 != nil {
+			// the history stays running until Timeout, so the next runs return ErrRunning until then
+			return true, count, fmt.Errorf("%w (cannot save the failed status: %v)", err, er2)
+		}
+		return true, count, err
+	}
+	_, err = m.History.Doc(m.Id).Set(ctx, History{Status: StatusCompleted, Count: count, StartedAt: &now, CompletedAt: &completedAt})
+	return true, count, err
+}
+
+func (m *Migration[S, D]) migrate(ctx context.Context) (int64, error) {
+	var count int64
+	models := make([]D, 0, m.BatchSize)
+	var err error
+	f.Iterate[S](ctx, m.Source.Query, m.idIndex, m.createdTimeIndex, m.updatedTimeIndex, nil)(func(obj S, er1 error) bool {
+		if er1 != nil {
+			err = er1
+			return false
+		}
+		model, er2 := m.Transform(ctx, &obj)
+		if er2 != nil {
+			err = er2
+			return false
+		}
+		if model == nil {
+			return true
+		}
+		m.setId(&obj, model)
+		models = append(models, *model)
+		if len(models) >= m.BatchSize {
+			if _, er3 := batch.SaveMany[D](ctx, m.client, m.Target, models, m.targetIdIndex); er3 != nil {
+				err = er3
+				return false
+			}
+			count = count + int64(len(models))
+			models = models[:0]
+		}
+		return true
+	})
+	if err != nil {
+		return count, err
+	}
+	if len(models) > 0 {
+		if _, err = batch.SaveMany[D](ctx, m.client, m.Target, models, m.targetIdIndex); err != nil {
+			return count, err
+		}
+		count = count + int64(len(models))
+	}
+	return count, nil
+}
+
+func (m *Migration[S, D]) setId(source *S, model *D) {
+	id := reflect.ValueOf(model).Elem().Field(m.targetIdIndex)
+	if id.Kind() == reflect.String && len(id.String()) == 0 {
+		sourceId := reflect.ValueOf(source).Elem().Field(m.idIndex)
+		if sourceId.Kind() == reflect.String {
+			id.SetString(sourceId.String())
+		}
+	}
+}
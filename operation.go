@@ -0,0 +1,94 @@
+package firestore
+
+import (
+	"fmt"
+	"reflect"
+
+	"cloud.google.com/go/firestore"
+)
+
+const (
+	OperatorSet             = "set"
+	OperatorIncrement       = "increment"
+	OperatorArrayUnion      = "arrayUnion"
+	OperatorArrayRemove     = "arrayRemove"
+	OperatorServerTimestamp = "serverTimestamp"
+	OperatorDelete          = "delete"
+)
+
+// FieldOperation is an atomic operation on a field, Field is the json name.
+type FieldOperation struct {
+	Field    string      `json:"field,omitempty"`
+	Operator string      `json:"operator,omitempty"`
+	Value    interface{} `json:"value,omitempty"`
+}
+
+func SetField(field string, value interface{}) FieldOperation {
+	return FieldOperation{Field: field, Operator: OperatorSet, Value: value}
+}
+
+// Increment adds n to the field, n must be an integer or a float.
+func Increment(field string, n interface{}) FieldOperation {
+	return FieldOperation{Field: field, Operator: OperatorIncrement, Value: n}
+}
+func ArrayUnion(field string, values ...interface{}) FieldOperation {
+	return FieldOperation{Field: field, Operator: OperatorArrayUnion, Value: values}
+}
+func ArrayRemove(field string, values ...interface{}) FieldOperation {
+	return FieldOperation{Field: field, Operator: OperatorArrayRemove, Value: values}
+}
+func ServerTimestamp(field string) FieldOperation {
+	return FieldOperation{Field: field, Operator: OperatorServerTimestamp}
+}
+func DeleteField(field string) FieldOperation {
+	return FieldOperation{Field: field, Operator: OperatorDelete}
+}
+
+// BuildOperations translates the operations to updates, the json names are mapped to firestore names by maps.
+func BuildOperations(operations []FieldOperation, maps map[string]string) ([]firestore.Update, error) {
+	updates := make([]firestore.Update, 0, len(operations))
+	for _, op := range operations {
+		name, ok := maps[op.Field]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a field", op.Field)
+		}
+		var value interface{}
+		switch op.Operator {
+		case OperatorSet:
+			value = op.Value
+		case OperatorIncrement:
+			value = firestore.Increment(op.Value)
+		case OperatorArrayUnion:
+			value = firestore.ArrayUnion(toValues(op.Value)...)
+		case OperatorArrayRemove:
+			value = firestore.ArrayRemove(toValues(op.Value)...)
+		case OperatorServerTimestamp:
+			value = firestore.ServerTimestamp
+		case OperatorDelete:
+			value = firestore.Delete
+		default:
+			return nil, fmt.Errorf("operator '%s' is not supported", op.Operator)
+		}
+		updates = append(updates, firestore.Update{FieldPath: firestore.FieldPath{name}, Value: value})
+	}
+	return updates, nil
+}
+
+// toValues expands a slice or an array, such as []string, into its elements. []byte is a single value.
+func toValues(value interface{}) []interface{} {
+	if values, ok := value.([]interface{}); ok {
+		return values
+	}
+	if _, ok := value.([]byte); ok {
+		return []interface{}{value}
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return []interface{}{value}
+	}
+	values := make([]interface{}, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
@@ -8,9 +8,11 @@ import (
 )
 
 type PasscodeRepository struct {
+	client        *firestore.Client
 	collection    *firestore.CollectionRef
 	passcodeName  string
 	expiredAtName string
+	ttlName       string
 }
 func NewPasscodeService(client *firestore.Client, collectionName string, options ...string) *PasscodeRepository {
 	return NewPasscodeRepository(client, collectionName, options...)
@@ -27,13 +29,22 @@ func NewPasscodeRepository(client *firestore.Client, collectionName string, opti
 	} else {
 		passcodeName = "passcode"
 	}
-	return &PasscodeRepository{client.Collection(collectionName), passcodeName, expiredAtName}
+	return &PasscodeRepository{client: client, collection: client.Collection(collectionName), passcodeName: passcodeName, expiredAtName: expiredAtName}
+}
+// NewPasscodeRepositoryWithTTL also writes expiredAt into ttlName, which can be configured as a Firestore TTL policy field.
+func NewPasscodeRepositoryWithTTL(client *firestore.Client, collectionName string, ttlName string, options ...string) *PasscodeRepository {
+	repo := NewPasscodeRepository(client, collectionName, options...)
+	repo.ttlName = ttlName
+	return repo
 }
 
 func (s *PasscodeRepository) Save(ctx context.Context, id string, passcode string, expiredAt time.Time) (int64, error) {
 	pass := make(map[string]interface{})
 	pass[s.passcodeName] = passcode
 	pass[s.expiredAtName] = expiredAt
+	if len(s.ttlName) > 0 {
+		pass[s.ttlName] = expiredAt
+	}
 	_, err := s.collection.Doc(id).Set(ctx, pass)
 	if err != nil {
 		return 0, err
@@ -60,6 +71,10 @@ func (s *PasscodeRepository) Load(ctx context.Context, id string) (string, time.
 func (s *PasscodeRepository) Delete(ctx context.Context, id string) (int64, error) {
 	return deleteOne(ctx, s.collection, id)
 }
+// DeleteExpired deletes the passcodes which expired before now, in chunks of batchSize.
+func (s *PasscodeRepository) DeleteExpired(ctx context.Context, batchSize int) (int64, error) {
+	return DeleteExpired(ctx, s.client, s.collection, s.expiredAtName, time.Now(), batchSize)
+}
 func deleteOne(ctx context.Context, collection *firestore.CollectionRef, docID string) (int64, error) {
 	_, err := collection.Doc(docID).Delete(ctx, firestore.Exists)
 	if err != nil {
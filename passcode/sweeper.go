@@ -0,0 +1,88 @@
+package passcode
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// DeleteExpired deletes the documents whose expiredAtName is before expiredAt, in transactions of batchSize documents.
+func DeleteExpired(ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, expiredAtName string, expiredAt time.Time, batchSize int) (int64, error) {
+	if batchSize <= 0 || batchSize > 500 {
+		batchSize = 500
+	}
+	var count int64
+	for {
+		docs, err := collection.Where(expiredAtName, "<", expiredAt).Select().Limit(batchSize).Documents(ctx).GetAll()
+		if err != nil {
+			return count, err
+		}
+		if len(docs) == 0 {
+			return count, nil
+		}
+		err = client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+			for _, doc := range docs {
+				if er1 := tx.Delete(doc.Ref); er1 != nil {
+					return er1
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return count, err
+		}
+		count = count + int64(len(docs))
+		if len(docs) < batchSize {
+			return count, nil
+		}
+	}
+}
+
+const DefaultInterval = time.Minute
+
+// Sweeper deletes the expired documents periodically.
+type Sweeper struct {
+	client        *firestore.Client
+	collection    *firestore.CollectionRef
+	expiredAtName string
+	BatchSize     int
+	Interval      time.Duration
+	LogError      func(ctx context.Context, msg string)
+}
+
+// NewSweeper creates a sweeper, the interval is DefaultInterval if it is not positive. options is the expired time field name, "expiredAt" by default.
+func NewSweeper(client *firestore.Client, collectionName string, interval time.Duration, batchSize int, options ...string) *Sweeper {
+	expiredAtName := "expiredAt"
+	if len(options) >= 1 && len(options[0]) > 0 {
+		expiredAtName = options[0]
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Sweeper{client: client, collection: client.Collection(collectionName), expiredAtName: expiredAtName, BatchSize: batchSize, Interval: interval}
+}
+
+func (s *Sweeper) Sweep(ctx context.Context) (int64, error) {
+	return DeleteExpired(ctx, s.client, s.collection, s.expiredAtName, time.Now(), s.BatchSize)
+}
+
+// Run sweeps every Interval, or DefaultInterval if Interval is not positive, until the context is done.
+func (s *Sweeper) Run(ctx context.Context) {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Sweep(ctx); err != nil && s.LogError != nil {
+			s.LogError(ctx, err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
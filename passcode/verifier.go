@@ -0,0 +1,201 @@
+package passcode
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+type Status int64
+
+const (
+	Valid    Status = 1
+	Invalid  Status = 0
+	Expired  Status = -1
+	Locked   Status = -2
+	NotFound Status = -3
+)
+
+// PasscodeVerifier stores the keyed hash of the salted passcode only, and verifies it with an attempts limit.
+// The secret is kept on the server, so the short passcodes cannot be brute forced from the stored hashes.
+type PasscodeVerifier struct {
+	client        *firestore.Client
+	collection    *firestore.CollectionRef
+	secret        []byte
+	passcodeName  string
+	expiredAtName string
+	saltName      string
+	attemptsName  string
+	ttlName       string
+	MaxAttempts   int64
+}
+
+// NewPasscodeVerifier creates a verifier, options are the expiredAt, passcode, salt and attempts field names.
+// The passcode is locked after maxAttempts failed attempts, it is never locked if maxAttempts <= 0.
+func NewPasscodeVerifier(client *firestore.Client, collectionName string, secret []byte, maxAttempts int64, options ...string) *PasscodeVerifier {
+	if len(secret) == 0 {
+		panic("secret cannot be empty")
+	}
+	expiredAtName := "expiredAt"
+	passcodeName := "passcode"
+	saltName := "salt"
+	attemptsName := "attempts"
+	if len(options) >= 1 && len(options[0]) > 0 {
+		expiredAtName = options[0]
+	}
+	if len(options) >= 2 && len(options[1]) > 0 {
+		passcodeName = options[1]
+	}
+	if len(options) >= 3 && len(options[2]) > 0 {
+		saltName = options[2]
+	}
+	if len(options) >= 4 && len(options[3]) > 0 {
+		attemptsName = options[3]
+	}
+	return &PasscodeVerifier{
+		client:        client,
+		collection:    client.Collection(collectionName),
+		secret:        secret,
+		passcodeName:  passcodeName,
+		expiredAtName: expiredAtName,
+		saltName:      saltName,
+		attemptsName:  attemptsName,
+		MaxAttempts:   maxAttempts,
+	}
+}
+
+// NewPasscodeVerifierWithTTL also writes expiredAt into ttlName, which can be configured as a Firestore TTL policy field.
+func NewPasscodeVerifierWithTTL(client *firestore.Client, collectionName string, secret []byte, maxAttempts int64, ttlName string, options ...string) *PasscodeVerifier {
+	verifier := NewPasscodeVerifier(client, collectionName, secret, maxAttempts, options...)
+	verifier.ttlName = ttlName
+	return verifier
+}
+
+// Save stores the hash of the passcode with a new salt in a transaction.
+// The failed attempts of the previous passcode are kept if it has not expired, so requesting a new passcode does not clear the lockout:
+// a locked id stays locked until its passcode expires without being saved again.
+func (s *PasscodeVerifier) Save(ctx context.Context, id string, passcode string, expiredAt time.Time) (int64, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return 0, err
+	}
+	pass := make(map[string]interface{})
+	pass[s.passcodeName] = Hash(s.secret, salt, passcode)
+	pass[s.saltName] = hex.EncodeToString(salt)
+	pass[s.expiredAtName] = expiredAt
+	if len(s.ttlName) > 0 {
+		pass[s.ttlName] = expiredAt
+	}
+	ref := s.collection.Doc(id)
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var attempts int64
+		doc, err := tx.Get(ref)
+		if err != nil && grpcstatus.Code(err) != codes.NotFound {
+			return err
+		}
+		if err == nil {
+			attempts = s.keptAttempts(doc.Data(), time.Now())
+		}
+		pass[s.attemptsName] = attempts
+		return tx.Set(ref, pass)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// Verify checks the passcode in a transaction. A failed attempt is counted, a valid passcode is deleted.
+func (s *PasscodeVerifier) Verify(ctx context.Context, id string, passcode string) (Status, error) {
+	ref := s.collection.Doc(id)
+	var status Status
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			if grpcstatus.Code(err) == codes.NotFound {
+				status = NotFound
+				return nil
+			}
+			return err
+		}
+		data := doc.Data()
+		current, _ := data[s.attemptsName].(int64)
+		var attempts int64
+		status, attempts, err = s.check(data, passcode, time.Now())
+		if err != nil {
+			return err
+		}
+		if status == Valid {
+			return tx.Delete(ref)
+		}
+		if attempts != current {
+			return tx.Update(ref, []firestore.Update{{Path: s.attemptsName, Value: attempts}})
+		}
+		return nil
+	})
+	if err != nil {
+		return Invalid, err
+	}
+	return status, nil
+}
+
+// check returns the status of the passcode against the stored data, and the attempts count after this attempt.
+func (s *PasscodeVerifier) check(data map[string]interface{}, passcode string, now time.Time) (Status, int64, error) {
+	attempts, _ := data[s.attemptsName].(int64)
+	expiredAt, _ := data[s.expiredAtName].(time.Time)
+	if !expiredAt.After(now) {
+		return Expired, attempts, nil
+	}
+	if s.MaxAttempts > 0 && attempts >= s.MaxAttempts {
+		return Locked, attempts, nil
+	}
+	hash, _ := data[s.passcodeName].(string)
+	sv, _ := data[s.saltName].(string)
+	salt, err := hex.DecodeString(sv)
+	if err != nil {
+		return Invalid, attempts, err
+	}
+	if subtle.ConstantTimeCompare([]byte(Hash(s.secret, salt, passcode)), []byte(hash)) == 1 {
+		return Valid, attempts, nil
+	}
+	attempts = attempts + 1
+	if s.MaxAttempts > 0 && attempts >= s.MaxAttempts {
+		return Locked, attempts, nil
+	}
+	return Invalid, attempts, nil
+}
+
+// keptAttempts returns the attempts count of the stored passcode if it has not expired, or 0.
+func (s *PasscodeVerifier) keptAttempts(data map[string]interface{}, now time.Time) int64 {
+	expiredAt, _ := data[s.expiredAtName].(time.Time)
+	if !expiredAt.After(now) {
+		return 0
+	}
+	attempts, _ := data[s.attemptsName].(int64)
+	return attempts
+}
+
+func (s *PasscodeVerifier) Delete(ctx context.Context, id string) (int64, error) {
+	return deleteOne(ctx, s.collection, id)
+}
+
+// Hash returns the hex encoded HMAC-SHA256 of the salt and the passcode, keyed by the secret.
+func Hash(secret []byte, salt []byte, passcode string) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write(salt)
+	h.Write([]byte(passcode))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DeleteExpired deletes the passcodes which expired before now, in chunks of batchSize.
+func (s *PasscodeVerifier) DeleteExpired(ctx context.Context, batchSize int) (int64, error) {
+	return DeleteExpired(ctx, s.client, s.collection, s.expiredAtName, time.Now(), batchSize)
+}
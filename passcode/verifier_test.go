@@ -0,0 +1,105 @@
+package passcode
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestHash(t *testing.T) {
+	secret := []byte("secret")
+	salt := []byte("salt")
+	hash := Hash(secret, salt, "123456")
+	if hash != Hash(secret, salt, "123456") {
+		t.Errorf("Hash must return the same hash for the same input")
+	}
+	if len(hash) != 64 {
+		t.Errorf("Hash returns %s, expected 64 hex characters", hash)
+	}
+	if hash == Hash([]byte("other"), salt, "123456") {
+		t.Errorf("Hash must depend on the secret")
+	}
+	if hash == Hash(secret, []byte("other"), "123456") {
+		t.Errorf("Hash must depend on the salt")
+	}
+	if hash == Hash(secret, salt, "123457") {
+		t.Errorf("Hash must depend on the passcode")
+	}
+}
+
+func newTestVerifier(maxAttempts int64) *PasscodeVerifier {
+	return &PasscodeVerifier{
+		secret:        []byte("secret"),
+		passcodeName:  "passcode",
+		expiredAtName: "expiredAt",
+		saltName:      "salt",
+		attemptsName:  "attempts",
+		MaxAttempts:   maxAttempts,
+	}
+}
+
+func newTestData(s *PasscodeVerifier, passcode string, expiredAt time.Time, attempts int64) map[string]interface{} {
+	salt := []byte("0123456789abcdef")
+	return map[string]interface{}{
+		s.passcodeName:  Hash(s.secret, salt, passcode),
+		s.saltName:      hex.EncodeToString(salt),
+		s.expiredAtName: expiredAt,
+		s.attemptsName:  attempts,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Minute)
+	tests := []struct {
+		name        string
+		maxAttempts int64
+		passcode    string
+		expiredAt   time.Time
+		attempts    int64
+		status      Status
+		result      int64
+	}{
+		{"valid", 3, "123456", later, 0, Valid, 0},
+		{"valid after failed attempts", 3, "123456", later, 2, Valid, 2},
+		{"invalid is counted", 3, "000000", later, 0, Invalid, 1},
+		{"last failed attempt locks", 3, "000000", later, 2, Locked, 3},
+		{"locked rejects the valid passcode", 3, "123456", later, 3, Locked, 3},
+		{"never locked without max attempts", 0, "000000", later, 10, Invalid, 11},
+		{"expired", 3, "123456", now, 0, Expired, 0},
+		{"expired is not counted", 3, "000000", now.Add(-time.Minute), 1, Expired, 1},
+	}
+	for _, test := range tests {
+		s := newTestVerifier(test.maxAttempts)
+		data := newTestData(s, "123456", test.expiredAt, test.attempts)
+		status, attempts, err := s.check(data, test.passcode, now)
+		if err != nil {
+			t.Errorf("%s: check returns error %v", test.name, err)
+			continue
+		}
+		if status != test.status || attempts != test.result {
+			t.Errorf("%s: check returns %d, %d, expected %d, %d", test.name, status, attempts, test.status, test.result)
+		}
+	}
+}
+
+func TestCheckInvalidSalt(t *testing.T) {
+	s := newTestVerifier(3)
+	now := time.Now()
+	data := newTestData(s, "123456", now.Add(time.Minute), 0)
+	data[s.saltName] = "not hex"
+	if _, _, err := s.check(data, "123456", now); err == nil {
+		t.Errorf("check must return an error for an invalid salt")
+	}
+}
+
+func TestKeptAttempts(t *testing.T) {
+	s := newTestVerifier(3)
+	now := time.Now()
+	if attempts := s.keptAttempts(newTestData(s, "123456", now.Add(time.Minute), 3), now); attempts != 3 {
+		t.Errorf("keptAttempts of a passcode which has not expired returns %d, expected 3", attempts)
+	}
+	if attempts := s.keptAttempts(newTestData(s, "123456", now.Add(-time.Minute), 3), now); attempts != 0 {
+		t.Errorf("keptAttempts of an expired passcode returns %d, expected 0", attempts)
+	}
+}
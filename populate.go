@@ -0,0 +1,197 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+)
+
+// Populate loads the documents referenced by the fields which have the "ref" tag with GetAll, and binds them into these fields.
+// The tag is `ref:"collection,IdFieldName"`, IdFieldName is the name of the string or []string field of the referenced ids.
+// The collection can be a pattern with {tenantId}, such as "tenants/{tenantId}/customers", resolved from the tenant id of the context.
+// The populated field can be S, *S or a slice of S or *S, and should have the `firestore:"-"` tag.
+// fields are the names or json names of the populated fields, all populated fields are loaded if fields is empty.
+func Populate[T any](ctx context.Context, client *firestore.Client, models []T, fields ...string) error {
+	if len(models) == 0 {
+		return nil
+	}
+	var t T
+	modelType := reflect.TypeOf(t)
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	values := make([]reflect.Value, 0, len(models))
+	for i := range models {
+		v := reflect.Indirect(reflect.ValueOf(&models[i]).Elem())
+		if v.IsValid() {
+			values = append(values, v)
+		}
+	}
+	numField := modelType.NumField()
+	for i := 0; i < numField; i++ {
+		field := modelType.Field(i)
+		tag, ok := field.Tag.Lookup("ref")
+		if !ok || !isPopulated(field, fields) {
+			continue
+		}
+		err := populateField(ctx, client, values, modelType, i, tag)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isPopulated(field reflect.StructField, fields []string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	json := field.Name
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		json = strings.Split(tag, ",")[0]
+	}
+	for _, name := range fields {
+		if name == field.Name || name == json {
+			return true
+		}
+	}
+	return false
+}
+
+func populateField(ctx context.Context, client *firestore.Client, values []reflect.Value, modelType reflect.Type, index int, tag string) error {
+	field := modelType.Field(index)
+	tags := strings.Split(tag, ",")
+	if len(tags) < 2 {
+		return fmt.Errorf("ref tag of %s must be 'collection,IdFieldName'", field.Name)
+	}
+	idIndex, _, _ := FindFieldByName(modelType, strings.TrimSpace(tags[1]))
+	if idIndex < 0 {
+		return fmt.Errorf("%s struct requires id field which name is '%s'", modelType.Name(), tags[1])
+	}
+	refType := field.Type
+	isSlice := refType.Kind() == reflect.Slice
+	if isSlice {
+		refType = refType.Elem()
+	}
+	isPointer := refType.Kind() == reflect.Ptr
+	if isPointer {
+		refType = refType.Elem()
+	}
+	if refType.Kind() != reflect.Struct {
+		return fmt.Errorf("%s must be a struct, a pointer to a struct or a slice", field.Name)
+	}
+	refIdIndex, _, _ := FindIdField(refType)
+
+	ids := make([]string, 0)
+	exist := make(map[string]bool)
+	for _, v := range values {
+		for _, id := range getIds(v.Field(idIndex)) {
+			if len(id) > 0 && !exist[id] {
+				exist[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	collection := client.Collection(strings.TrimSpace(tags[0]))
+	if strings.Contains(tags[0], TenantId) {
+		c, er1 := NewTenantResolver(client, strings.TrimSpace(tags[0]))(ctx)
+		if er1 != nil {
+			return er1
+		}
+		collection = c
+	}
+	docs, err := GetAll(ctx, client, collection, ids)
+	if err != nil {
+		return err
+	}
+	refs := make(map[string]reflect.Value, len(docs))
+	for id, doc := range docs {
+		ref := reflect.New(refType)
+		err = doc.DataTo(ref.Interface())
+		if err != nil {
+			return err
+		}
+		if refIdIndex >= 0 {
+			ref.Elem().Field(refIdIndex).Set(reflect.ValueOf(id))
+		}
+		refs[id] = ref
+	}
+	for _, v := range values {
+		fv := v.Field(index)
+		if isSlice {
+			keys := getIds(v.Field(idIndex))
+			slice := reflect.MakeSlice(fv.Type(), 0, len(keys))
+			for _, id := range keys {
+				if ref, ok := refs[id]; ok {
+					slice = reflect.Append(slice, refValue(ref, isPointer))
+				}
+			}
+			fv.Set(slice)
+			continue
+		}
+		keys := getIds(v.Field(idIndex))
+		if len(keys) == 0 {
+			continue
+		}
+		if ref, ok := refs[keys[0]]; ok {
+			fv.Set(refValue(ref, isPointer))
+		}
+	}
+	return nil
+}
+
+func refValue(ref reflect.Value, isPointer bool) reflect.Value {
+	if isPointer {
+		return ref
+	}
+	return ref.Elem()
+}
+
+func getIds(v reflect.Value) []string {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.String:
+		return []string{v.String()}
+	case reflect.Slice:
+		ids := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if id, ok := reflect.Indirect(v.Index(i)).Interface().(string); ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	default:
+		return nil
+	}
+}
+
+// GetAll loads the existing documents with GetAll in chunks of 500 ids, keyed by ids.
+func GetAll(ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, ids []string) (map[string]*firestore.DocumentSnapshot, error) {
+	docs := make(map[string]*firestore.DocumentSnapshot, len(ids))
+	for start := 0; start < len(ids); start += 500 {
+		end := start + 500
+		if end > len(ids) {
+			end = len(ids)
+		}
+		refs := make([]*firestore.DocumentRef, 0, end-start)
+		for _, id := range ids[start:end] {
+			refs = append(refs, collection.Doc(id))
+		}
+		snapshots, err := client.GetAll(ctx, refs)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range snapshots {
+			if doc != nil && doc.Exists() {
+				docs[doc.Ref.ID] = doc
+			}
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,124 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// MakeJsonMap maps firestore names to json names, the reverse of MakeFirestoreMap.
+func MakeJsonMap(modelType reflect.Type) map[string]string {
+	maps := MakeFirestoreMap(modelType)
+	jsonMap := make(map[string]string, len(maps))
+	for k, v := range maps {
+		jsonMap[v] = k
+	}
+	return jsonMap
+}
+
+// ToFirestoreFields converts json names to firestore names. It returns an error if any name is unknown.
+func ToFirestoreFields(fields []string, maps map[string]string) ([]string, error) {
+	names := make([]string, 0, len(fields))
+	var unknowns []string
+	for _, field := range fields {
+		if name, ok := maps[field]; ok {
+			names = append(names, name)
+		} else {
+			unknowns = append(unknowns, field)
+		}
+	}
+	if len(unknowns) > 0 {
+		return nil, fmt.Errorf("unknown fields: %s", strings.Join(unknowns, ","))
+	}
+	return names, nil
+}
+
+// ToJsonMap returns the loaded fields of the document keyed by json names, with id and create/update times if their json names are not empty.
+func ToJsonMap(doc *firestore.DocumentSnapshot, jsonMap map[string]string, idJson string, createdTimeJson string, updatedTimeJson string) map[string]interface{} {
+	data := doc.Data()
+	res := make(map[string]interface{}, len(data)+3)
+	for k, v := range data {
+		if name, ok := jsonMap[k]; ok {
+			res[name] = v
+		}
+	}
+	if len(idJson) > 0 {
+		res[idJson] = doc.Ref.ID
+	}
+	if len(createdTimeJson) > 0 {
+		res[createdTimeJson] = doc.CreateTime
+	}
+	if len(updatedTimeJson) > 0 {
+		res[updatedTimeJson] = doc.UpdateTime
+	}
+	return res
+}
+
+func SelectFields(collection *firestore.CollectionRef, fields []string) firestore.Query {
+	if len(fields) == 0 {
+		return collection.Query
+	}
+	return collection.Select(fields...)
+}
+
+// LoadDocument loads the document with the given firestore fields only, it returns nil if the document does not exist.
+func LoadDocument(ctx context.Context, collection *firestore.CollectionRef, id string, fields []string) (*firestore.DocumentSnapshot, error) {
+	if len(fields) == 0 {
+		doc, err := collection.Doc(id).Get(ctx)
+		if err != nil {
+			if strings.HasSuffix(err.Error(), " not found") {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return doc, nil
+	}
+	iter := collection.Select(fields...).Where(firestore.DocumentID, "==", collection.Doc(id)).Limit(1).Documents(ctx)
+	defer iter.Stop()
+	doc, err := iter.Next()
+	if err == iterator.Done {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func LoadFields(ctx context.Context, collection *firestore.CollectionRef, id string, fields []string, res interface{}) (bool, *firestore.DocumentSnapshot, error) {
+	doc, er1 := LoadDocument(ctx, collection, id, fields)
+	if er1 != nil || doc == nil {
+		return false, doc, er1
+	}
+	er2 := doc.DataTo(res)
+	return true, doc, er2
+}
+
+func BuildSearchMapResult(ctx context.Context, collection *firestore.CollectionRef, query []Query, fields []string, sort map[string]firestore.Direction, limit int64, refId string, jsonMap map[string]string, idJson string, createdTimeJson string, updatedTimeJson string) ([]map[string]interface{}, string, error) {
+	if geo, _, _ := GetGeoRadius(query); geo != nil {
+		return nil, "", fmt.Errorf("%s is not supported for map results", GeoRadiusOperator)
+	}
+	queries, er0 := BuildQuerySearch(ctx, collection, query, fields, sort, int(limit), refId)
+	if er0 != nil {
+		return nil, "", er0
+	}
+	iter := queries.Documents(ctx)
+	results := make([]map[string]interface{}, 0)
+	var lastId string
+	for {
+		doc, er1 := iter.Next()
+		if er1 == iterator.Done {
+			break
+		}
+		if er1 != nil {
+			return results, "", er1
+		}
+		lastId = doc.Ref.ID
+		results = append(results, ToJsonMap(doc, jsonMap, idJson, createdTimeJson, updatedTimeJson))
+	}
+	return results, lastId, nil
+}
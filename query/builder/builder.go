@@ -25,18 +25,27 @@ func (b *Builder[F]) BuildQuery(filter F) ([]f.Query, []string) {
 	return BuildQueryByType(filter, b.ModelType)
 }
 
-var operators = map[string]string{
-	"=":                  "==",
-	"==":                 "==",
-	"!=":                 "!=",
-	">":                  ">",
-	">=":                 ">=",
-	"<":                  "<",
-	"<=":                 "<=",
-	"array-contains":     "array-contains",
-	"array-contains-any": "array-contains-any",
-	"in":                 "in",
-	"not-in":             "not-in",
+func init() {
+	Register[search.TimeRange](buildTimeRange)
+	Register[search.DateRange](buildDateRange)
+	Register[search.NumberRange](func(path string, operator string, v search.NumberRange) []f.Query {
+		return buildRange(path, v.Min, v.Max, v.Lower, v.Upper)
+	})
+	Register[search.Int64Range](func(path string, operator string, v search.Int64Range) []f.Query {
+		return buildRange(path, v.Min, v.Max, v.Lower, v.Upper)
+	})
+	Register[search.IntRange](func(path string, operator string, v search.IntRange) []f.Query {
+		return buildRange(path, v.Min, v.Max, v.Lower, v.Upper)
+	})
+	Register[search.Int32Range](func(path string, operator string, v search.Int32Range) []f.Query {
+		return buildRange(path, v.Min, v.Max, v.Lower, v.Upper)
+	})
+	Register[f.GeoRadius](func(path string, operator string, v f.GeoRadius) []f.Query {
+		if v.Radius <= 0 {
+			return nil
+		}
+		return []f.Query{{Path: path, Operator: f.GeoRadiusOperator, Value: v}}
+	})
 }
 
 func BuildQueryByType(filter interface{}, resultModelType reflect.Type) ([]f.Query, []string) {
@@ -57,10 +66,15 @@ func BuildQueryByType(filter interface{}, resultModelType reflect.Type) ([]f.Que
 		}
 		filterType := value.Type()
 		operator := "=="
+		var convertOperator Convert
 		if key, ok := filterType.Field(i).Tag.Lookup("operator"); ok && len(key) > 0 {
-			oper, ok2 := operators[key]
+			oper, convert, ok2 := getOperator(key)
 			if ok2 {
-				operator = oper
+				if convert != nil {
+					convertOperator = convert
+				} else {
+					operator = oper
+				}
 			}
 		}
 
@@ -106,98 +120,12 @@ func BuildQueryByType(filter interface{}, resultModelType reflect.Type) ([]f.Que
 		} else if len(fsName) == 0 {
 			continue
 		}
-		if len(psv) > 0 {
+		if convertOperator != nil {
+			query = append(query, convertOperator(fsName, operator, x)...)
+		} else if convert, ok := getConverter(reflect.TypeOf(x)); ok {
+			query = append(query, convert(fsName, operator, x)...)
+		} else if len(psv) > 0 {
 			query = append(query, f.Query{Path: fsName, Operator: operator, Value: psv})
-		} else if rangeTime, ok := x.(search.TimeRange); ok {
-			timeQuery := make([]f.Query, 0)
-			if rangeTime.Min == nil {
-				timeQuery = []f.Query{{Path: fsName, Operator: "<=", Value: rangeTime.Max}}
-			} else if rangeTime.Max == nil {
-				timeQuery = []f.Query{{Path: fsName, Operator: ">=", Value: rangeTime.Min}}
-			} else {
-				timeQuery = []f.Query{{Path: fsName, Operator: ">=", Value: rangeTime.Min}, {Path: fsName, Operator: "<=", Value: rangeTime.Max}}
-			}
-			query = append(query, timeQuery...)
-		} else if rangeDate, ok := x.(search.DateRange); ok {
-			dateQuery := make([]f.Query, 0)
-			if rangeDate.Min == nil && rangeDate.Max == nil {
-				continue
-			} else if rangeDate.Min == nil {
-				dateQuery = []f.Query{{Path: fsName, Operator: "<=", Value: rangeDate.Max}}
-			} else if rangeDate.Max == nil {
-				dateQuery = []f.Query{{Path: fsName, Operator: ">=", Value: rangeDate.Min}}
-			} else {
-				dateQuery = []f.Query{{Path: fsName, Operator: ">=", Value: rangeDate.Min}, {Path: fsName, Operator: "<=", Value: rangeDate.Max}}
-			}
-			query = append(query, dateQuery...)
-		} else if numberRange, ok := x.(search.NumberRange); ok {
-			numQuery := make([]f.Query, 0)
-
-			if numberRange.Min != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: ">=", Value: *numberRange.Min})
-			} else if numberRange.Lower != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: ">", Value: *numberRange.Lower})
-			}
-			if numberRange.Max != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: "<=", Value: *numberRange.Max})
-			} else if numberRange.Upper != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: "<", Value: *numberRange.Upper})
-			}
-
-			if len(numQuery) > 0 {
-				query = append(query, numQuery...)
-			}
-		} else if numberRange, ok := x.(search.Int64Range); ok {
-			numQuery := make([]f.Query, 0)
-
-			if numberRange.Min != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: ">=", Value: *numberRange.Min})
-			} else if numberRange.Lower != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: ">", Value: *numberRange.Lower})
-			}
-			if numberRange.Max != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: "<=", Value: *numberRange.Max})
-			} else if numberRange.Upper != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: "<", Value: *numberRange.Upper})
-			}
-
-			if len(numQuery) > 0 {
-				query = append(query, numQuery...)
-			}
-		} else if numberRange, ok := x.(search.IntRange); ok {
-			numQuery := make([]f.Query, 0)
-
-			if numberRange.Min != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: ">=", Value: *numberRange.Min})
-			} else if numberRange.Lower != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: ">", Value: *numberRange.Lower})
-			}
-			if numberRange.Max != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: "<=", Value: *numberRange.Max})
-			} else if numberRange.Upper != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: "<", Value: *numberRange.Upper})
-			}
-
-			if len(numQuery) > 0 {
-				query = append(query, numQuery...)
-			}
-		} else if numberRange, ok := x.(search.Int32Range); ok {
-			numQuery := make([]f.Query, 0)
-
-			if numberRange.Min != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: ">=", Value: *numberRange.Min})
-			} else if numberRange.Lower != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: ">", Value: *numberRange.Lower})
-			}
-			if numberRange.Max != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: "<=", Value: *numberRange.Max})
-			} else if numberRange.Upper != nil {
-				numQuery = append(numQuery, f.Query{Path: fsName, Operator: "<", Value: *numberRange.Upper})
-			}
-
-			if len(numQuery) > 0 {
-				query = append(query, numQuery...)
-			}
 		} else if kind == reflect.Slice {
 			if reflect.Indirect(reflect.ValueOf(x)).Len() > 0 {
 				if operator == "==" {
@@ -214,6 +142,39 @@ func BuildQueryByType(filter interface{}, resultModelType reflect.Type) ([]f.Que
 	return query, fields
 }
 
+func buildTimeRange(path string, operator string, rangeTime search.TimeRange) []f.Query {
+	if rangeTime.Min == nil {
+		return []f.Query{{Path: path, Operator: "<=", Value: rangeTime.Max}}
+	} else if rangeTime.Max == nil {
+		return []f.Query{{Path: path, Operator: ">=", Value: rangeTime.Min}}
+	}
+	return []f.Query{{Path: path, Operator: ">=", Value: rangeTime.Min}, {Path: path, Operator: "<=", Value: rangeTime.Max}}
+}
+func buildDateRange(path string, operator string, rangeDate search.DateRange) []f.Query {
+	if rangeDate.Min == nil && rangeDate.Max == nil {
+		return nil
+	} else if rangeDate.Min == nil {
+		return []f.Query{{Path: path, Operator: "<=", Value: rangeDate.Max}}
+	} else if rangeDate.Max == nil {
+		return []f.Query{{Path: path, Operator: ">=", Value: rangeDate.Min}}
+	}
+	return []f.Query{{Path: path, Operator: ">=", Value: rangeDate.Min}, {Path: path, Operator: "<=", Value: rangeDate.Max}}
+}
+func buildRange[N any](path string, min *N, max *N, lower *N, upper *N) []f.Query {
+	numQuery := make([]f.Query, 0)
+	if min != nil {
+		numQuery = append(numQuery, f.Query{Path: path, Operator: ">=", Value: *min})
+	} else if lower != nil {
+		numQuery = append(numQuery, f.Query{Path: path, Operator: ">", Value: *lower})
+	}
+	if max != nil {
+		numQuery = append(numQuery, f.Query{Path: path, Operator: "<=", Value: *max})
+	} else if upper != nil {
+		numQuery = append(numQuery, f.Query{Path: path, Operator: "<", Value: *upper})
+	}
+	return numQuery
+}
+
 func getFieldByJson(modelType reflect.Type, jsonName string) (int, string, string) {
 	numField := modelType.NumField()
 	for i := 0; i < numField; i++ {
@@ -0,0 +1,93 @@
+package builder
+
+import (
+	"reflect"
+	"sync"
+
+	f "github.com/core-go/firestore"
+)
+
+// Convert builds the queries of a filter field. path is the firestore name of the field,
+// operator is the firestore operator resolved from the "operator" tag (default "==").
+type Convert func(path string, operator string, value interface{}) []f.Query
+
+var (
+	mu        sync.RWMutex
+	operators = map[string]string{
+		"=":                  "==",
+		"==":                 "==",
+		"!=":                 "!=",
+		">":                  ">",
+		">=":                 ">=",
+		"<":                  "<",
+		"<=":                 "<=",
+		"array-contains":     "array-contains",
+		"array-contains-any": "array-contains-any",
+		"in":                 "in",
+		"not-in":             "not-in",
+	}
+	operatorConverters = make(map[string]Convert)
+	converters         = make(map[reflect.Type]Convert)
+)
+
+// RegisterOperator maps an "operator" tag to a firestore operator.
+func RegisterOperator(key string, operator string) {
+	mu.Lock()
+	defer mu.Unlock()
+	operators[key] = operator
+	delete(operatorConverters, key)
+}
+
+// RegisterOperatorFunc registers an "operator" tag which builds its own queries, for example a "prefix" operator.
+func RegisterOperatorFunc(key string, convert Convert) {
+	mu.Lock()
+	defer mu.Unlock()
+	operatorConverters[key] = convert
+	delete(operators, key)
+}
+
+// RegisterType registers the converter of a filter value type. Pointer types are registered by their element type.
+func RegisterType(valueType reflect.Type, convert Convert) {
+	if valueType.Kind() == reflect.Ptr {
+		valueType = valueType.Elem()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	converters[valueType] = convert
+}
+
+// Register registers the converter of the filter value type V. If V is a pointer type, it is registered by its element type
+// and the converter receives a pointer to a copy of the value, because the filter values are dereferenced.
+func Register[V any](convert func(path string, operator string, value V) []f.Query) {
+	valueType := reflect.TypeOf((*V)(nil)).Elem()
+	if valueType.Kind() != reflect.Ptr {
+		RegisterType(valueType, func(path string, operator string, value interface{}) []f.Query {
+			return convert(path, operator, value.(V))
+		})
+		return
+	}
+	RegisterType(valueType, func(path string, operator string, value interface{}) []f.Query {
+		if v, ok := value.(V); ok {
+			return convert(path, operator, v)
+		}
+		p := reflect.New(valueType.Elem())
+		p.Elem().Set(reflect.ValueOf(value))
+		return convert(path, operator, p.Interface().(V))
+	})
+}
+
+func getOperator(key string) (string, Convert, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if operator, ok := operators[key]; ok {
+		return operator, nil, true
+	}
+	convert, ok := operatorConverters[key]
+	return "", convert, ok
+}
+func getConverter(valueType reflect.Type) (Convert, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	convert, ok := converters[valueType]
+	return convert, ok
+}
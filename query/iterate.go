@@ -0,0 +1,29 @@
+package query
+
+import (
+	"context"
+
+	f "github.com/core-go/firestore"
+)
+
+// Iterate streams all documents instead of loading them into a slice, see f.Iterate.
+func (s *Loader[T]) Iterate(ctx context.Context) func(yield func(T, error) bool) {
+	collection, err := s.collection(ctx)
+	if err != nil {
+		return f.IterateError[T](err)
+	}
+	return f.Iterate[T](ctx, collection.Query, s.idIndex, s.createdTimeIndex, s.updatedTimeIndex, s.Map)
+}
+
+// IterateSearch streams all documents matched by the filter, without limit.
+func (b *Query[T, F]) IterateSearch(ctx context.Context, filter F) func(yield func(T, error) bool) {
+	collection, err := b.collection(ctx)
+	if err != nil {
+		return f.IterateError[T](err)
+	}
+	query, fields := b.BuildQuery(filter)
+
+	s := b.GetSort(filter)
+	sort := b.BuildSort(s, b.ModelType)
+	return f.IterateSearch[T](ctx, collection, query, fields, sort, b.idIndex, b.createdTimeIndex, b.updatedTimeIndex, b.Map)
+}
@@ -0,0 +1,20 @@
+package query
+
+import (
+	"context"
+
+	f "github.com/core-go/firestore"
+)
+
+// LoadMany loads the models in the order of ids, with nil for the missing documents, see f.LoadMany.
+func (s *Loader[T]) LoadMany(ctx context.Context, ids []string) ([]*T, []string, error) {
+	collection, err := s.collection(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f.LoadMany[T](ctx, client, collection, ids, s.idIndex, s.createdTimeIndex, s.updatedTimeIndex, s.Map)
+}
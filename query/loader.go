@@ -4,18 +4,25 @@ import (
 	"cloud.google.com/go/firestore"
 	"context"
 	"fmt"
-	"google.golang.org/api/iterator"
 	"reflect"
 
 	f "github.com/core-go/firestore"
 )
 
 type Loader[T any] struct {
+	client           *firestore.Client
 	Collection       *firestore.CollectionRef
 	Map              func(*T)
 	idIndex          int
+	idJson           string
 	createdTimeIndex int
+	createdTimeJson  string
 	updatedTimeIndex int
+	updatedTimeJson  string
+	fieldMap         map[string]string
+	jsonMap          map[string]string
+	resolve          f.CollectionResolver
+	resolveClient    f.ClientResolver
 }
 
 func NewLoader[T any](client *firestore.Client, collectionName string, opts ...string) *Loader[T] {
@@ -23,6 +30,7 @@ func NewLoader[T any](client *firestore.Client, collectionName string, opts ...s
 }
 func NewLoaderWithMap[T any](client *firestore.Client, collectionName string, mp func(*T), opts ...string) *Loader[T] {
 	idx := -1
+	var idJson string
 	var idFieldName string
 	var createdTimeFieldName string
 	var updatedTimeFieldName string
@@ -41,12 +49,12 @@ func NewLoaderWithMap[T any](client *firestore.Client, collectionName string, mp
 		panic("T must be a struct")
 	}
 	if len(idFieldName) == 0 {
-		idx, _, _ = f.FindIdField(modelType)
+		idx, _, idJson = f.FindIdField(modelType)
 		if idx < 0 {
 			panic(fmt.Sprintf("%s struct requires id field which has bson tag '_id'", modelType.Name()))
 		}
 	} else {
-		idx, _, _ = f.FindFieldByName(modelType, idFieldName)
+		idx, idJson, _ = f.FindFieldByName(modelType, idFieldName)
 		if idx < 0 {
 			panic(fmt.Sprintf("%s struct requires id field which id name is '%s'", modelType.Name(), idFieldName))
 		}
@@ -56,8 +64,9 @@ func NewLoaderWithMap[T any](client *firestore.Client, collectionName string, mp
 		panic(fmt.Sprintf("%s type of %s struct must be string", modelType.Field(idx).Name, modelType.Name()))
 	}
 	ctIdx := -1
+	var createdTimeJson string
 	if len(createdTimeFieldName) >= 0 {
-		ctIdx, _, _ = f.FindFieldByName(modelType, createdTimeFieldName)
+		ctIdx, createdTimeJson, _ = f.FindFieldByName(modelType, createdTimeFieldName)
 		if ctIdx >= 0 {
 			ctn := modelType.Field(ctIdx).Type.String()
 			if ctn != "*time.Time" {
@@ -66,8 +75,9 @@ func NewLoaderWithMap[T any](client *firestore.Client, collectionName string, mp
 		}
 	}
 	utIdx := -1
+	var updatedTimeJson string
 	if len(updatedTimeFieldName) >= 0 {
-		utIdx, _, _ = f.FindFieldByName(modelType, updatedTimeFieldName)
+		utIdx, updatedTimeJson, _ = f.FindFieldByName(modelType, updatedTimeFieldName)
 		if utIdx >= 0 {
 			ctn := modelType.Field(utIdx).Type.String()
 			if ctn != "*time.Time" {
@@ -75,51 +85,25 @@ func NewLoaderWithMap[T any](client *firestore.Client, collectionName string, mp
 			}
 		}
 	}
-	return &Loader[T]{Collection: client.Collection(collectionName), Map: mp, idIndex: idx, createdTimeIndex: ctIdx, updatedTimeIndex: utIdx}
+	var collection *firestore.CollectionRef
+	if client != nil {
+		collection = client.Collection(collectionName)
+	}
+	return &Loader[T]{client: client, Collection: collection, Map: mp, idIndex: idx, idJson: idJson, createdTimeIndex: ctIdx, createdTimeJson: createdTimeJson, updatedTimeIndex: utIdx, updatedTimeJson: updatedTimeJson, fieldMap: f.MakeFirestoreMap(modelType), jsonMap: f.MakeJsonMap(modelType)}
 }
 
 func (s *Loader[T]) All(ctx context.Context) ([]T, error) {
-	iter := s.Collection.Documents(ctx)
-	var objs []T
-	for {
-		doc, er1 := iter.Next()
-		if er1 == iterator.Done {
-			break
-		}
-		if er1 != nil {
-			return nil, er1
-		}
-		var obj T
-		er2 := doc.DataTo(&obj)
-		if er2 != nil {
-			return objs, er2
-		}
-
-		f.BindCommonFields(&obj, doc, s.idIndex, s.createdTimeIndex, s.updatedTimeIndex)
-		if s.Map != nil {
-			s.Map(&obj)
-		}
-		objs = append(objs, obj)
-	}
-	return objs, nil
+	return s.AllFields(ctx, nil)
 }
 
 func (s *Loader[T]) Load(ctx context.Context, id string) (*T, error) {
-	var obj T
-	ok, doc, err := f.Load(ctx, s.Collection, id, &obj)
-	if err != nil {
-		return nil, err
-	}
-	if !ok {
-		return nil, nil
-	}
-	f.BindCommonFields(&obj, doc, s.idIndex, s.createdTimeIndex, s.updatedTimeIndex)
-	if s.Map != nil {
-		s.Map(&obj)
-	}
-	return &obj, nil
+	return s.LoadFields(ctx, id, nil)
 }
 
 func (s *Loader[T]) Exist(ctx context.Context, id string) (bool, error) {
-	return f.Exist(ctx, s.Collection, id)
+	collection, err := s.collection(ctx)
+	if err != nil {
+		return false, err
+	}
+	return f.Exist(ctx, collection, id)
 }
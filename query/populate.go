@@ -0,0 +1,16 @@
+package query
+
+import (
+	"context"
+
+	f "github.com/core-go/firestore"
+)
+
+// Populate loads the referenced documents of the models, see f.Populate.
+func (s *Loader[T]) Populate(ctx context.Context, objs []T, fields ...string) error {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return err
+	}
+	return f.Populate[T](ctx, client, objs, fields...)
+}
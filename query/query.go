@@ -41,12 +41,16 @@ func NewQuery[T any, F any](client *firestore.Client, collectionName string, bui
 	return NewQueryWithSort[T, F](client, collectionName, buildQuery, f.BuildSort, getSort, nil, opts...)
 }
 func (b *Query[T, F]) Search(ctx context.Context, filter F, limit int64, nextPageToken string) ([]T, string, error) {
+	collection, err := b.collection(ctx)
+	if err != nil {
+		return nil, "", err
+	}
 	query, fields := b.BuildQuery(filter)
 
 	s := b.GetSort(filter)
 	sort := b.BuildSort(s, b.ModelType)
 	var objs []T
-	refId, err := f.BuildSearchResult(ctx, b.Collection, &objs, query, fields, sort, limit, nextPageToken, b.idIndex, b.createdTimeIndex, b.updatedTimeIndex)
+	refId, err := f.BuildSearchResult(ctx, collection, &objs, query, fields, sort, limit, nextPageToken, b.idIndex, b.createdTimeIndex, b.updatedTimeIndex)
 	if b.Map != nil {
 		l := len(objs)
 		for i := 0; i < l; i++ {
@@ -55,3 +59,14 @@ func (b *Query[T, F]) Search(ctx context.Context, filter F, limit int64, nextPag
 	}
 	return objs, refId, err
 }
+func (b *Query[T, F]) SearchMap(ctx context.Context, filter F, limit int64, nextPageToken string) ([]map[string]interface{}, string, error) {
+	collection, err := b.collection(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	query, fields := b.BuildQuery(filter)
+
+	s := b.GetSort(filter)
+	sort := b.BuildSort(s, b.ModelType)
+	return f.BuildSearchMapResult(ctx, collection, query, fields, sort, limit, nextPageToken, b.jsonMap, b.idJson, b.createdTimeJson, b.updatedTimeJson)
+}
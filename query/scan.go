@@ -0,0 +1,21 @@
+package query
+
+import (
+	"context"
+
+	f "github.com/core-go/firestore"
+)
+
+// Scan reads the whole collection in partitions with at most workers goroutines, handle is called concurrently.
+// The partitions are queried in the client of the resolved collection, see NewLoaderWithClientResolver.
+func (s *Loader[T]) Scan(ctx context.Context, partitions int, workers int, handle func(context.Context, *T) error, progress func(f.ScanProgress)) (int64, error) {
+	collection, err := s.collection(ctx)
+	if err != nil {
+		return 0, err
+	}
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return f.Scan[T](ctx, client, collection, partitions, workers, s.idIndex, s.createdTimeIndex, s.updatedTimeIndex, s.Map, handle, progress)
+}
@@ -0,0 +1,62 @@
+package query
+
+import (
+	"context"
+	"reflect"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+)
+
+// NewTenantLoader creates the loader which resolves the collection from the tenant id in the context for each call, see f.NewTenantResolver.
+// Collection is nil, and the operations return f.ErrNoTenant if there is no tenant id in the context.
+func NewTenantLoader[T any](client *firestore.Client, collectionPattern string, opts ...string) *Loader[T] {
+	return NewLoaderWithResolver[T](client, f.NewTenantResolver(client, collectionPattern), nil, opts...)
+}
+
+// NewLoaderWithResolver creates the loader which resolves the collection for each call. The collection must be in the database of client,
+// use NewLoaderWithClientResolver if the database is resolved for each call.
+func NewLoaderWithResolver[T any](client *firestore.Client, resolve f.CollectionResolver, mp func(*T), opts ...string) *Loader[T] {
+	loader := NewLoaderWithMap[T](client, "", mp, opts...)
+	loader.Collection = nil
+	loader.resolve = resolve
+	return loader
+}
+
+// NewLoaderWithClientResolver creates the loader which resolves the client and the collection for each call, such as the client of the database in the context,
+// see client.Registry. If collectionName contains {tenantId}, it is replaced by the tenant id of the context.
+func NewLoaderWithClientResolver[T any](resolveClient f.ClientResolver, collectionName string, mp func(*T), opts ...string) *Loader[T] {
+	loader := NewLoaderWithResolver[T](nil, f.NewCollectionResolver(resolveClient, collectionName), mp, opts...)
+	loader.resolveClient = resolveClient
+	return loader
+}
+
+func NewTenantQuery[T any, F any](client *firestore.Client, collectionPattern string, buildQuery func(F) ([]f.Query, []string), getSort func(interface{}) string, opts ...string) *Query[T, F] {
+	return NewQueryWithResolver[T, F](client, f.NewTenantResolver(client, collectionPattern), buildQuery, f.BuildSort, getSort, nil, opts...)
+}
+func NewQueryWithResolver[T any, F any](client *firestore.Client, resolve f.CollectionResolver, buildQuery func(F) ([]f.Query, []string), buildSort func(string, reflect.Type) map[string]firestore.Direction, getSort func(interface{}) string, mp func(*T), opts ...string) *Query[T, F] {
+	q := NewQueryWithSort[T, F](client, "", buildQuery, buildSort, getSort, mp, opts...)
+	q.Collection = nil
+	q.resolve = resolve
+	return q
+}
+
+func NewQueryWithClientResolver[T any, F any](resolveClient f.ClientResolver, collectionName string, buildQuery func(F) ([]f.Query, []string), getSort func(interface{}) string, mp func(*T), opts ...string) *Query[T, F] {
+	q := NewQueryWithResolver[T, F](nil, f.NewCollectionResolver(resolveClient, collectionName), buildQuery, f.BuildSort, getSort, mp, opts...)
+	q.resolveClient = resolveClient
+	return q
+}
+
+func (s *Loader[T]) collection(ctx context.Context) (*firestore.CollectionRef, error) {
+	if s.resolve == nil {
+		return s.Collection, nil
+	}
+	return s.resolve(ctx)
+}
+
+func (s *Loader[T]) getClient(ctx context.Context) (*firestore.Client, error) {
+	if s.resolveClient == nil {
+		return s.client, nil
+	}
+	return s.resolveClient(ctx)
+}
@@ -0,0 +1,46 @@
+package query
+
+import (
+	"context"
+
+	f "github.com/core-go/firestore"
+)
+
+// TxLoader is the view of the loader bound to a transaction.
+type TxLoader[T any] struct {
+	base *Loader[T]
+	tx   *f.Tx
+}
+
+func (s *Loader[T]) WithTx(tx *f.Tx) *TxLoader[T] {
+	return &TxLoader[T]{base: s, tx: tx}
+}
+
+func (t *TxLoader[T]) Load(ctx context.Context, id string) (*T, error) {
+	collection, err := t.base.collection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := t.tx.GetDocument(collection.Doc(id))
+	if err != nil || doc == nil {
+		return nil, err
+	}
+	var obj T
+	err = doc.DataTo(&obj)
+	if err != nil {
+		return nil, err
+	}
+	f.BindCommonFields(&obj, doc, t.base.idIndex, t.base.createdTimeIndex, t.base.updatedTimeIndex)
+	if t.base.Map != nil {
+		t.base.Map(&obj)
+	}
+	return &obj, nil
+}
+func (t *TxLoader[T]) Exist(ctx context.Context, id string) (bool, error) {
+	collection, err := t.base.collection(ctx)
+	if err != nil {
+		return false, err
+	}
+	doc, err := t.tx.GetDocument(collection.Doc(id))
+	return doc != nil, err
+}
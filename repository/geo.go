@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+)
+
+// NewGeoRepository creates the Repository which maintains the geohash field from the location field (*latlng.LatLng) on Create, Save and Update.
+func NewGeoRepository[T any](client *firestore.Client, collectionName string, locationFieldName string, geohashFieldName string, options ...string) *Repository[T] {
+	a := NewRepository[T](client, collectionName, options...)
+	locationIndex, locationJson, _ := f.FindFieldByName(a.ModelType, locationFieldName)
+	if locationIndex < 0 {
+		panic(fmt.Sprintf("%s struct requires location field which name is '%s'", a.ModelType.Name(), locationFieldName))
+	}
+	if a.ModelType.Field(locationIndex).Type.String() != "*latlng.LatLng" {
+		panic(fmt.Sprintf("%s type of %s struct must be *latlng.LatLng", locationFieldName, a.ModelType.Name()))
+	}
+	geohashIndex, geohashJson, _ := f.FindFieldByName(a.ModelType, geohashFieldName)
+	if geohashIndex < 0 {
+		panic(fmt.Sprintf("%s struct requires geohash field which name is '%s'", a.ModelType.Name(), geohashFieldName))
+	}
+	if a.ModelType.Field(geohashIndex).Type.String() != "string" {
+		panic(fmt.Sprintf("%s type of %s struct must be string", geohashFieldName, a.ModelType.Name()))
+	}
+	a.locationIndex = locationIndex
+	a.geohashIndex = geohashIndex
+	a.locationJson = locationJson
+	a.geohashJson = geohashJson
+	return a
+}
+
+// patchGeohash sets the geohash in the json map if the location is in the map.
+func (a *Repository[T]) patchGeohash(data map[string]interface{}) error {
+	if a.geohashIndex < 0 {
+		return nil
+	}
+	location, ok := data[a.locationJson]
+	if !ok {
+		return nil
+	}
+	geohash, err := f.GetGeohash(location)
+	if err != nil {
+		return err
+	}
+	data[a.geohashJson] = geohash
+	return nil
+}
+
+// geohashFields adds the geohash field to the json fields if the location field is in the fields.
+func (a *Repository[T]) geohashFields(fields []string) []string {
+	if a.geohashIndex < 0 {
+		return fields
+	}
+	hasLocation := false
+	for _, field := range fields {
+		if field == a.geohashJson {
+			return fields
+		}
+		if field == a.locationJson {
+			hasLocation = true
+		}
+	}
+	if hasLocation {
+		return append(fields, a.geohashJson)
+	}
+	return fields
+}
+
+// geohashOperations adds the operation of the geohash field if the location field is set or deleted.
+func (a *Repository[T]) geohashOperations(operations []f.FieldOperation) ([]f.FieldOperation, error) {
+	if a.geohashIndex < 0 {
+		return operations, nil
+	}
+	for _, op := range operations {
+		if op.Field == a.geohashJson {
+			return operations, nil
+		}
+	}
+	for _, op := range operations {
+		if op.Field != a.locationJson {
+			continue
+		}
+		switch op.Operator {
+		case f.OperatorSet:
+			geohash, err := f.GetGeohash(op.Value)
+			if err != nil {
+				return nil, err
+			}
+			return append(operations, f.SetField(a.geohashJson, geohash)), nil
+		case f.OperatorDelete:
+			return append(operations, f.DeleteField(a.geohashJson)), nil
+		}
+	}
+	return operations, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/firestore"
+	f "github.com/core-go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Modify runs the field operations in one update, and increases the version field if the Repository has a version field.
+// The version field cannot be modified by an operation, because it is increased by Modify.
+func (a *Repository[T]) Modify(ctx context.Context, id string, operations ...f.FieldOperation) (int64, error) {
+	collection, err := a.collection(ctx)
+	if err != nil {
+		return -1, err
+	}
+	if a.versionIndex >= 0 {
+		for _, op := range operations {
+			if a.Map[op.Field] == a.versionFirestore {
+				return -1, fmt.Errorf("version field %s cannot be modified", op.Field)
+			}
+		}
+	}
+	operations, err = a.geohashOperations(operations)
+	if err != nil {
+		return -1, err
+	}
+	updates, err := f.BuildOperations(operations, a.Map)
+	if err != nil {
+		return -1, err
+	}
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	if a.versionIndex >= 0 {
+		updates = append(updates, firestore.Update{FieldPath: firestore.FieldPath{a.versionFirestore}, Value: firestore.Increment(1)})
+	}
+	_, err = collection.Doc(id).Update(ctx, updates)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return 0, nil
+		}
+		return -1, err
+	}
+	return 1, nil
+}
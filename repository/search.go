@@ -39,11 +39,26 @@ func NewSearchRepositoryWithSort[T any, F any](client *firestore.Client, collect
 	return &SearchRepository[T, F]{Repository: repo, BuildQuery: buildQuery, BuildSort: buildSort, GetSort: getSort}
 }
 func (b *SearchRepository[T, F]) Search(ctx context.Context, filter F, limit int64, nextPageToken string) ([]T, string, error) {
+	collection, err := b.collection(ctx)
+	if err != nil {
+		return nil, "", err
+	}
 	query, fields := b.BuildQuery(filter)
 
 	s := b.GetSort(filter)
 	sort := b.BuildSort(s, b.ModelType)
 	var objs []T
-	refId, err := f.BuildSearchResult(ctx, b.Collection, &objs, query, fields, sort, limit, nextPageToken, b.idIndex, b.createdTimeIndex, b.updatedTimeIndex)
+	refId, err := f.BuildSearchResult(ctx, collection, &objs, query, fields, sort, limit, nextPageToken, b.idIndex, b.createdTimeIndex, b.updatedTimeIndex)
 	return objs, refId, err
 }
+func (b *SearchRepository[T, F]) SearchMap(ctx context.Context, filter F, limit int64, nextPageToken string) ([]map[string]interface{}, string, error) {
+	collection, err := b.collection(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	query, fields := b.BuildQuery(filter)
+
+	s := b.GetSort(filter)
+	sort := b.BuildSort(s, b.ModelType)
+	return f.BuildSearchMapResult(ctx, collection, query, fields, sort, limit, nextPageToken, b.jsonMap, b.idJson, b.createdTimeJson, b.updatedTimeJson)
+}
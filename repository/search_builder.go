@@ -17,12 +17,17 @@ type SearchBuilder[T any, F any] struct {
 	GetSort          func(interface{}) string
 	Map              func(*T)
 	idIndex          int
+	idJson           string
 	createdTimeIndex int
+	createdTimeJson  string
 	updatedTimeIndex int
+	updatedTimeJson  string
+	jsonMap          map[string]string
 }
 
 func NewSearchBuilderWithSort[T any, F any](client *firestore.Client, collectionName string, buildQuery func(F) ([]f.Query, []string), getSort func(interface{}) string, buildSort func(s string, modelType reflect.Type) map[string]firestore.Direction, mp func(*T), opts ...string) *SearchBuilder[T, F] {
 	idx := -1
+	var idJson string
 	var idFieldName string
 	var createdTimeFieldName string
 	var updatedTimeFieldName string
@@ -41,12 +46,12 @@ func NewSearchBuilderWithSort[T any, F any](client *firestore.Client, collection
 		panic("T must be a struct")
 	}
 	if len(idFieldName) == 0 {
-		idx, _, _ = f.FindIdField(modelType)
+		idx, _, idJson = f.FindIdField(modelType)
 		if idx < 0 {
 			panic("Require Id field of " + modelType.Name() + " struct define _id bson tag.")
 		}
 	} else {
-		idx, _, _ = f.FindFieldByName(modelType, idFieldName)
+		idx, idJson, _ = f.FindFieldByName(modelType, idFieldName)
 		if idx < 0 {
 			panic(fmt.Sprintf("%s struct requires id field which id name is '%s'", modelType.Name(), idFieldName))
 		}
@@ -56,8 +61,9 @@ func NewSearchBuilderWithSort[T any, F any](client *firestore.Client, collection
 		panic(fmt.Sprintf("%s type of %s struct must be string", modelType.Field(idx).Name, modelType.Name()))
 	}
 	ctIdx := -1
+	var createdTimeJson string
 	if len(createdTimeFieldName) >= 0 {
-		ctIdx, _, _ = f.FindFieldByName(modelType, createdTimeFieldName)
+		ctIdx, createdTimeJson, _ = f.FindFieldByName(modelType, createdTimeFieldName)
 		if ctIdx >= 0 {
 			ctn := modelType.Field(ctIdx).Type.String()
 			if ctn != "*time.Time" {
@@ -66,8 +72,9 @@ func NewSearchBuilderWithSort[T any, F any](client *firestore.Client, collection
 		}
 	}
 	utIdx := -1
+	var updatedTimeJson string
 	if len(updatedTimeFieldName) >= 0 {
-		utIdx, _, _ = f.FindFieldByName(modelType, updatedTimeFieldName)
+		utIdx, updatedTimeJson, _ = f.FindFieldByName(modelType, updatedTimeFieldName)
 		if utIdx >= 0 {
 			ctn := modelType.Field(utIdx).Type.String()
 			if ctn != "*time.Time" {
@@ -76,7 +83,7 @@ func NewSearchBuilderWithSort[T any, F any](client *firestore.Client, collection
 		}
 	}
 	collection := client.Collection(collectionName)
-	return &SearchBuilder[T, F]{Collection: collection, ModelType: modelType, BuildQuery: buildQuery, BuildSort: buildSort, GetSort: getSort, Map: mp, idIndex: idx, createdTimeIndex: ctIdx, updatedTimeIndex: utIdx}
+	return &SearchBuilder[T, F]{Collection: collection, ModelType: modelType, BuildQuery: buildQuery, BuildSort: buildSort, GetSort: getSort, Map: mp, idIndex: idx, idJson: idJson, createdTimeIndex: ctIdx, createdTimeJson: createdTimeJson, updatedTimeIndex: utIdx, updatedTimeJson: updatedTimeJson, jsonMap: f.MakeJsonMap(modelType)}
 }
 func NewSearchBuilderWithMap[T any, F any](client *firestore.Client, collectionName string, buildQuery func(F) ([]f.Query, []string), getSort func(interface{}) string, mp func(*T), opts ...string) *SearchBuilder[T, F] {
 	return NewSearchBuilderWithSort[T, F](client, collectionName, buildQuery, getSort, f.BuildSort, mp, opts...)
@@ -99,3 +106,19 @@ func (b *SearchBuilder[T, F]) Search(ctx context.Context, filter F, limit int64,
 	}
 	return objs, refId, err
 }
+func (b *SearchBuilder[T, F]) SearchMap(ctx context.Context, filter F, limit int64, nextPageToken string) ([]map[string]interface{}, string, error) {
+	query, fields := b.BuildQuery(filter)
+
+	s := b.GetSort(filter)
+	sort := b.BuildSort(s, b.ModelType)
+	return f.BuildSearchMapResult(ctx, b.Collection, query, fields, sort, limit, nextPageToken, b.jsonMap, b.idJson, b.createdTimeJson, b.updatedTimeJson)
+}
+
+// IterateSearch streams all documents matched by the filter, without limit.
+func (b *SearchBuilder[T, F]) IterateSearch(ctx context.Context, filter F) func(yield func(T, error) bool) {
+	query, fields := b.BuildQuery(filter)
+
+	s := b.GetSort(filter)
+	sort := b.BuildSort(s, b.ModelType)
+	return f.IterateSearch[T](ctx, b.Collection, query, fields, sort, b.idIndex, b.createdTimeIndex, b.updatedTimeIndex, b.Map)
+}
@@ -0,0 +1,117 @@
+package firestore
+
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+type ScanProgress struct {
+	Partition  int   `json:"partition"`
+	Partitions int   `json:"partitions"`
+	Count      int64 `json:"count"`
+	Done       int   `json:"done"`
+	Total      int64 `json:"total"`
+}
+
+// ScanPartitions splits the collection into partitions by CollectionGroup.GetPartitionedQueries and scans them with at most workers goroutines.
+// handle is called concurrently, progress is called once per completed partition. The first error cancels the scan.
+// The partitioned queries cover all collections with the same id, such as the "users" sub collections of every tenant,
+// and the documents of the other collections are read then skipped. Each of them is billed as a read,
+// so the cost depends on all collections with the same id, not on this collection only.
+func ScanPartitions(ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, partitions int, workers int, handle func(context.Context, *firestore.DocumentSnapshot) error, progress func(ScanProgress)) (int64, error) {
+	if partitions <= 0 {
+		partitions = 1
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	queries, err := client.CollectionGroup(collection.ID).GetPartitionedQueries(ctx, partitions)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var total int64
+	var done int
+	var er0 error
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				count, er1 := scanPartition(ctx, queries[i], collection.Path, handle)
+				mu.Lock()
+				total = total + count
+				if er1 != nil {
+					if er0 == nil {
+						er0 = er1
+					}
+					cancel()
+				} else {
+					done = done + 1
+					if progress != nil {
+						progress(ScanProgress{Partition: i, Partitions: len(queries), Count: count, Done: done, Total: total})
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range queries {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	if er0 == nil && ctx.Err() != nil {
+		er0 = ctx.Err()
+	}
+	return total, er0
+}
+func scanPartition(ctx context.Context, query firestore.Query, path string, handle func(context.Context, *firestore.DocumentSnapshot) error) (int64, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+	var count int64
+	for {
+		doc, er1 := iter.Next()
+		if er1 == iterator.Done {
+			return count, nil
+		}
+		if er1 != nil {
+			return count, er1
+		}
+		// the partitions cover all collections with the same id, keep the documents of this collection only
+		if doc.Ref.Parent == nil || doc.Ref.Parent.Path != path {
+			continue
+		}
+		er2 := handle(ctx, doc)
+		if er2 != nil {
+			return count, er2
+		}
+		count = count + 1
+	}
+}
+
+// Scan decodes the documents of ScanPartitions into T and binds id and create/update times before calling handle.
+func Scan[T any](ctx context.Context, client *firestore.Client, collection *firestore.CollectionRef, partitions int, workers int, idIndex int, createdTimeIndex int, updatedTimeIndex int, mp func(*T), handle func(context.Context, *T) error, progress func(ScanProgress)) (int64, error) {
+	return ScanPartitions(ctx, client, collection, partitions, workers, func(ctx context.Context, doc *firestore.DocumentSnapshot) error {
+		var obj T
+		err := doc.DataTo(&obj)
+		if err != nil {
+			return err
+		}
+		BindCommonFields(&obj, doc, idIndex, createdTimeIndex, updatedTimeIndex)
+		if mp != nil {
+			mp(&obj)
+		}
+		return handle(ctx, &obj)
+	}, progress)
+}
@@ -61,7 +61,15 @@ func (b *SearchBuilder) Search(ctx context.Context, m interface{}, results inter
 	return refId, err
 }
 
+// BuildSearchResult loads the page after the document refId and returns the id of the last document as the next page token.
+// A GeoRadius search returns the first limit documents sorted by distance in one page: sort is ignored, and refId must be empty.
 func BuildSearchResult(ctx context.Context, collection *firestore.CollectionRef, results interface{}, query []Query, fields []string, sort map[string]firestore.Direction, limit int64, refId string, idIndex int, createdTimeIndex int, updatedTimeIndex int) (string, error) {
+	if geo, path, others := GetGeoRadius(query); geo != nil {
+		if len(refId) > 0 {
+			return "", fmt.Errorf("%s does not support next page token", GeoRadiusOperator)
+		}
+		return "", BuildGeoSearchResult(ctx, collection, results, *geo, path, others, fields, limit, idIndex, createdTimeIndex, updatedTimeIndex)
+	}
 	queries, er0 := BuildQuerySearch(ctx, collection, query, fields, sort, int(limit), refId)
 	if er0 != nil {
 		return "", er0
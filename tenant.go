@@ -0,0 +1,73 @@
+package firestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+)
+
+const TenantId = "{tenantId}"
+
+var ErrNoTenant = errors.New("tenant id is required in context")
+
+type tenantKey struct{}
+
+// CollectionResolver returns the collection of the current call, such as the collection of the tenant in the context.
+type CollectionResolver func(ctx context.Context) (*firestore.CollectionRef, error)
+
+// ClientResolver returns the client of the current call, such as the client of the database in the context.
+type ClientResolver func(ctx context.Context) (*firestore.Client, error)
+
+func WithTenant(ctx context.Context, tenantId string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantId)
+}
+
+// GetTenant returns the tenant id of the context, or an empty string.
+func GetTenant(ctx context.Context) string {
+	if tenantId, ok := ctx.Value(tenantKey{}).(string); ok {
+		return tenantId
+	}
+	return ""
+}
+
+// NewTenantResolver resolves the collection from a pattern which contains {tenantId},
+// such as "tenants/{tenantId}/users" for a sub collection or "{tenantId}_users" for a prefixed collection.
+// It returns ErrNoTenant if there is no tenant id in the context.
+func NewTenantResolver(client *firestore.Client, pattern string) CollectionResolver {
+	if !strings.Contains(pattern, TenantId) {
+		panic(fmt.Sprintf("collection pattern '%s' must contain %s", pattern, TenantId))
+	}
+	return func(ctx context.Context) (*firestore.CollectionRef, error) {
+		tenantId := GetTenant(ctx)
+		if len(tenantId) == 0 {
+			return nil, ErrNoTenant
+		}
+		if strings.Contains(tenantId, "/") {
+			return nil, fmt.Errorf("invalid tenant id '%s'", tenantId)
+		}
+		collection := client.Collection(strings.ReplaceAll(pattern, TenantId, tenantId))
+		if collection == nil {
+			return nil, fmt.Errorf("invalid collection path of tenant '%s'", tenantId)
+		}
+		return collection, nil
+	}
+}
+
+// NewCollectionResolver resolves the collection in the client of the current call.
+// If collectionName contains {tenantId}, it is replaced by the tenant id of the context, see NewTenantResolver.
+func NewCollectionResolver(resolveClient ClientResolver, collectionName string) CollectionResolver {
+	tenant := strings.Contains(collectionName, TenantId)
+	return func(ctx context.Context) (*firestore.CollectionRef, error) {
+		client, err := resolveClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if tenant {
+			return NewTenantResolver(client, collectionName)(ctx)
+		}
+		return client.Collection(collectionName), nil
+	}
+}
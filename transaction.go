@@ -0,0 +1,87 @@
+package firestore
+
+import (
+	"context"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+)
+
+// Tx is a transaction which keeps the documents read in the transaction,
+// so that the checks before a write reuse them, because all reads of a transaction must be before its writes.
+type Tx struct {
+	*firestore.Transaction
+	docs        map[string]*firestore.DocumentSnapshot
+	afterCommit []func()
+}
+
+func NewTx(tx *firestore.Transaction) *Tx {
+	return &Tx{Transaction: tx, docs: make(map[string]*firestore.DocumentSnapshot)}
+}
+
+// GetDocument reads the document once in the transaction, it returns nil if the document does not exist.
+func (t *Tx) GetDocument(ref *firestore.DocumentRef) (*firestore.DocumentSnapshot, error) {
+	if doc, ok := t.docs[ref.Path]; ok {
+		return doc, nil
+	}
+	doc, err := t.Get(ref)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), " not found") {
+			t.docs[ref.Path] = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+	t.docs[ref.Path] = doc
+	return doc, nil
+}
+
+// AfterCommit registers fn to be called by RunTransaction after the transaction is committed.
+// Changes of the caller's models must be deferred to it, because the function of the transaction can be run again.
+func (t *Tx) AfterCommit(fn func()) {
+	t.afterCommit = append(t.afterCommit, fn)
+}
+
+// UnitOfWork runs a function in a transaction, which is retried if it conflicts with another transaction.
+// The adapters used in the transaction must resolve their collections in the database of the transaction.
+type UnitOfWork struct {
+	client        *firestore.Client
+	resolveClient ClientResolver
+	options       []firestore.TransactionOption
+}
+
+func NewUnitOfWork(client *firestore.Client, options ...firestore.TransactionOption) *UnitOfWork {
+	return &UnitOfWork{client: client, options: options}
+}
+
+// NewUnitOfWorkWithResolver creates the unit of work which runs the transaction in the client of the current call, such as the client of the database in the context.
+func NewUnitOfWorkWithResolver(resolveClient ClientResolver, options ...firestore.TransactionOption) *UnitOfWork {
+	return &UnitOfWork{resolveClient: resolveClient, options: options}
+}
+
+func (u *UnitOfWork) Run(ctx context.Context, fn func(ctx context.Context, tx *Tx) error) error {
+	client := u.client
+	if u.resolveClient != nil {
+		c, err := u.resolveClient(ctx)
+		if err != nil {
+			return err
+		}
+		client = c
+	}
+	return RunTransaction(ctx, client, fn, u.options...)
+}
+
+// RunTransaction runs fn with a new Tx for each attempt of the transaction.
+func RunTransaction(ctx context.Context, client *firestore.Client, fn func(ctx context.Context, tx *Tx) error, options ...firestore.TransactionOption) error {
+	var t *Tx
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		t = NewTx(tx)
+		return fn(ctx, t)
+	}, options...)
+	if err == nil && t != nil {
+		for _, commit := range t.afterCommit {
+			commit()
+		}
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package firestore
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// MakeJsonIndex maps json names to field indexes, with the same json names as MakeFirestoreMap.
+func MakeJsonIndex(modelType reflect.Type) map[string]int {
+	indexes := make(map[string]int)
+	numField := modelType.NumField()
+	for i := 0; i < numField; i++ {
+		field := modelType.Field(i)
+		key := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			key = strings.Split(tag, ",")[0]
+		}
+		if tag, ok := field.Tag.Lookup("firestore"); ok {
+			if tag == "-" {
+				continue
+			}
+			if key == "-" {
+				key = strings.Split(tag, ",")[0]
+			}
+		} else if key == "-" {
+			key = field.Name
+		}
+		indexes[key] = i
+	}
+	return indexes
+}
+
+// BuildUpdates builds the updates of the fields of the model, the fields are json names.
+func BuildUpdates(model interface{}, fields []string, indexes map[string]int, maps map[string]string) ([]firestore.Update, error) {
+	mv := reflect.Indirect(reflect.ValueOf(model))
+	updates := make([]firestore.Update, 0, len(fields))
+	for _, field := range fields {
+		idx, ok1 := indexes[field]
+		name, ok2 := maps[field]
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("%s is not a field of %s", field, mv.Type().Name())
+		}
+		updates = append(updates, firestore.Update{FieldPath: firestore.FieldPath{name}, Value: mv.Field(idx).Interface()})
+	}
+	return updates, nil
+}
+
+// GetNonZeroFields returns the json names of the fields which are not zero, except the fields at the excluded indexes.
+func GetNonZeroFields(model interface{}, indexes map[string]int, excludes ...int) []string {
+	mv := reflect.Indirect(reflect.ValueOf(model))
+	fields := make([]string, 0)
+	for name, idx := range indexes {
+		exclude := false
+		for _, i := range excludes {
+			if i == idx {
+				exclude = true
+				break
+			}
+		}
+		if !exclude && !mv.Field(idx).IsZero() {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// MapToUpdates builds the updates of the json map, it returns an error for an unknown json name.
+func MapToUpdates(data map[string]interface{}, maps map[string]string) ([]firestore.Update, error) {
+	updates := make([]firestore.Update, 0, len(data))
+	for k, v := range data {
+		name, ok := maps[k]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a field", k)
+		}
+		updates = append(updates, firestore.Update{FieldPath: firestore.FieldPath{name}, Value: v})
+	}
+	return updates, nil
+}
+
+// ToFirestoreMap returns the fields of the model keyed by firestore names.
+func ToFirestoreMap(model interface{}, indexes map[string]int, maps map[string]string) map[string]interface{} {
+	mv := reflect.Indirect(reflect.ValueOf(model))
+	data := make(map[string]interface{}, len(indexes))
+	for json, idx := range indexes {
+		if name, ok := maps[json]; ok {
+			data[name] = mv.Field(idx).Interface()
+		}
+	}
+	return data
+}
+
+// GetChangedFields returns the fields of data whose values are different from the stored fields, both are keyed by firestore names.
+func GetChangedFields(stored map[string]interface{}, data map[string]interface{}) map[string]interface{} {
+	changed := make(map[string]interface{})
+	for k, v := range data {
+		if sv, ok := stored[k]; !ok || !IsEqual(sv, v) {
+			changed[k] = v
+		}
+	}
+	return changed
+}
+
+// IsEqual compares a value loaded from firestore with a value of a model, as firestore stores integers as int64 and times in UTC.
+func IsEqual(stored interface{}, value interface{}) bool {
+	rv := reflect.ValueOf(value)
+	for rv.IsValid() && rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return stored == nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return stored == nil
+	}
+	value = rv.Interface()
+	if t, ok := value.(time.Time); ok {
+		st, ok2 := stored.(time.Time)
+		return ok2 && st.Equal(t)
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := stored.(int64)
+		return ok && n == rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := stored.(int64)
+		return ok && n >= 0 && uint64(n) == rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		n, ok := stored.(float64)
+		return ok && n == rv.Float()
+	}
+	return reflect.DeepEqual(stored, value)
+}
@@ -4,6 +4,7 @@ import (
 	"cloud.google.com/go/firestore"
 	"context"
 	"fmt"
+	f "github.com/core-go/firestore"
 	"reflect"
 )
 
@@ -12,6 +13,7 @@ type Writer[T any] struct {
 	idx        int
 	Map        func(T)
 	isPointer  bool
+	resolve    f.CollectionResolver
 }
 
 func NewWriter[T any](client *firestore.Client, collectionName string, opts ...func(T)) *Writer[T] {
@@ -34,7 +36,10 @@ func NewWriter[T any](client *firestore.Client, collectionName string, opts ...f
 	if len(opts) >= 1 {
 		mp = opts[0]
 	}
-	collection := client.Collection(collectionName)
+	var collection *firestore.CollectionRef
+	if client != nil {
+		collection = client.Collection(collectionName)
+	}
 	return &Writer[T]{collection: collection, idx: idx, Map: mp, isPointer: isPointer}
 }
 
@@ -47,5 +52,29 @@ func (w *Writer[T]) Write(ctx context.Context, model T) error {
 		vo = reflect.Indirect(vo)
 	}
 	id := vo.Field(w.idx).Interface().(string)
-	return Save(ctx, w.collection, id, model)
+	collection := w.collection
+	if w.resolve != nil {
+		c, err := w.resolve(ctx)
+		if err != nil {
+			return err
+		}
+		collection = c
+	}
+	return Save(ctx, collection, id, model)
+}
+
+// NewTenantWriter creates the writer which resolves the collection from the tenant id in the context for each write, see f.NewTenantResolver.
+func NewTenantWriter[T any](client *firestore.Client, collectionPattern string, opts ...func(T)) *Writer[T] {
+	w := NewWriter[T](client, "", opts...)
+	w.collection = nil
+	w.resolve = f.NewTenantResolver(client, collectionPattern)
+	return w
+}
+
+// NewWriterWithResolver creates the writer which resolves the client and the collection for each write, such as the client of the database in the context.
+// If collectionName contains {tenantId}, it is replaced by the tenant id of the context.
+func NewWriterWithResolver[T any](resolveClient f.ClientResolver, collectionName string, opts ...func(T)) *Writer[T] {
+	w := NewWriter[T](nil, "", opts...)
+	w.resolve = f.NewCollectionResolver(resolveClient, collectionName)
+	return w
 }